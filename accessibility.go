@@ -0,0 +1,124 @@
+package bitreevis
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultDocumentTitle is the title of an accessible graphic without DocumentTitle.
+	defaultDocumentTitle = "Binary tree"
+	// maxDescribedNodes is the maximum number of nodes listed in a generated description.
+	maxDescribedNodes = 50
+)
+
+// describeTree returns a description of the tree made of nodes in order, for screen readers.
+func describeTree(nodes []*PlaceableNode) string {
+	if len(nodes) == 0 {
+		return "Empty binary tree."
+	}
+	count, height := 0, 0
+	for _, node := range nodes {
+		levels := node.Depth + 1
+		if node.Collapsed {
+			count += node.HiddenNodes
+			levels += node.HiddenHeight - 1
+		} else {
+			count++
+		}
+		if levels > height {
+			height = levels
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("Binary tree with ")
+	b.WriteString(countNoun(count, "node"))
+	b.WriteString(" and height ")
+	b.WriteString(strconv.Itoa(height))
+	b.WriteString(". In-order: ")
+	for i, node := range nodes {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		if i == maxDescribedNodes {
+			b.WriteString(ellipsis)
+			break
+		}
+		if node.Collapsed {
+			b.WriteString(ellipsis)
+		} else {
+			b.WriteString(node.Field)
+		}
+	}
+	b.WriteString(".")
+	return b.String()
+}
+
+// documentMetadata returns the title and the description of the document according to opt.
+// nodes are the nodes of the tree in order.
+func documentMetadata(nodes []*PlaceableNode, opt *RenderOption) (title, desc string) {
+	title, desc = opt.DocumentTitle, opt.DocumentDescription
+	if opt.Accessible {
+		if title == "" {
+			title = defaultDocumentTitle
+		}
+		if desc == "" {
+			desc = describeTree(nodes)
+		}
+	}
+	return title, desc
+}
+
+// rootAttributes returns the attributes of the svg root element with the document title and description.
+func rootAttributes(title, desc string, opt *RenderOption) []string {
+	if !opt.Accessible {
+		return nil
+	}
+	attrs := []string{`role="graphics-document document"`, `aria-roledescription="binary tree"`}
+	if title != "" {
+		attrs = append(attrs, `aria-labelledby="`+string(appendEscapedXML(nil, opt.ElementIDPrefix+"title"))+`"`)
+	}
+	if desc != "" {
+		attrs = append(attrs, `aria-describedby="`+string(appendEscapedXML(nil, opt.ElementIDPrefix+"desc"))+`"`)
+	}
+	return attrs
+}
+
+// addDocumentMetadata writes the title and desc elements of the document.
+func (sr *SvgRenderer) addDocumentMetadata(title, desc string, opt *RenderOption) {
+	if title != "" {
+		sr.svgCanvasBeginInlineShape("title", []svgAttribute{{key: "id", value: opt.ElementIDPrefix + "title"}})
+		sr.writeEscapedText(title)
+		sr.svgCanvasEndCustomShape("title")
+	}
+	if desc != "" {
+		sr.svgCanvasBeginInlineShape("desc", []svgAttribute{{key: "id", value: opt.ElementIDPrefix + "desc"}})
+		sr.writeEscapedText(desc)
+		sr.svgCanvasEndCustomShape("desc")
+	}
+}
+
+// nodeAriaLabel returns the label of node read by screen readers.
+func nodeAriaLabel(node *PlaceableNode) string {
+	if node.Collapsed {
+		return "collapsed subtree of " + countNoun(node.HiddenNodes, "node") + " and height " + strconv.Itoa(node.HiddenHeight)
+	}
+	return node.Field
+}
+
+// nodeDescription returns the description of node given by DescribedBiNode, or an empty string.
+func nodeDescription(node *PlaceableNode) string {
+	if described, ok := node.Source.(DescribedBiNode); ok && !node.Collapsed {
+		return described.GetDescription()
+	}
+	return ""
+}
+
+// countNoun returns n followed by noun, which is in plural form if n is not 1.
+func countNoun(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
@@ -0,0 +1,45 @@
+package bitreevis_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+type describedNode struct {
+	myNode
+	description string
+}
+
+func (n *describedNode) GetDescription() string {
+	return n.description
+}
+
+func TestSvgRenderer_NodeDescription(t *testing.T) {
+	root := &describedNode{myNode: myNode{Value: 2, Left: &myNode{Value: 1}}, description: "root <of> tree"}
+	content := renderForTest(t, root, nil)
+	requireWellFormed(t, content)
+	require.Contains(t, content, "<g >\n<title>root &lt;of&gt; tree</title>\n<circle")
+}
+
+func TestSvgRenderer_Accessible(t *testing.T) {
+	content := renderForTest(t, newBstForTest(), &bitreevis.RenderOption{Accessible: true, MaxDepth: 2})
+	requireWellFormed(t, content)
+	require.Contains(t, content, `role="graphics-document document"`)
+	require.Contains(t, content, `aria-labelledby="title"`)
+	require.Contains(t, content, `<title id="title" >Binary tree</title>`)
+	// 9 is collapsed into a placeholder
+	require.Contains(t, content, `<desc id="desc" >Binary tree with 8 nodes and height 4. In-order: 1, 2, 3, 5, 6, 7, 8, ….</desc>`)
+	require.Contains(t, content, `role="graphics-symbol" aria-label="7"`)
+	require.Contains(t, content, `aria-label="collapsed subtree of 1 node and height 1"`)
+
+	content = renderForTest(t, newBstForTest(), &bitreevis.RenderOption{DocumentTitle: "BST", DocumentDescription: "a search tree"})
+	require.Contains(t, content, `<title id="title" >BST</title>`)
+	require.Contains(t, content, `<desc id="desc" >a search tree</desc>`)
+	require.NotContains(t, content, "role=")
+
+	content = renderForTest(t, nil, &bitreevis.RenderOption{Accessible: true})
+	require.Contains(t, content, "Empty binary tree.")
+}
@@ -0,0 +1,112 @@
+package bitreevis
+
+import "strconv"
+
+const (
+	// arrayGap is the gap between the lowest node, or the sorted axis, and the array view.
+	arrayGap = 24
+	// arrayIndexTextSize is the font size of the indices below the cells of the array view.
+	arrayIndexTextSize = 12
+	// arrayIndexGap is the gap between the cells of the array view and their indices.
+	arrayIndexGap = 4
+)
+
+// arrayView is the array drawn below the tree of IndexedBiNode, in the coordinates of layout.
+type arrayView struct {
+	// cells are the nodes at every index of the array, nil if the node at an index is not drawn.
+	cells []*PlaceableNode
+	// left and top are the top left corner of the first cell, size is the width and the height of cells.
+	left, top, size float64
+}
+
+// collectArrayView returns the array view of nodes below the tree described by stats,
+// nil if no node is an IndexedBiNode. The array is centred below root.
+func collectArrayView(root *PlaceableNode, nodes []*PlaceableNode, stats *SizeLimitStat, opt *RenderOption) *arrayView {
+	var cells []*PlaceableNode
+	for _, node := range nodes {
+		indexed, ok := node.Source.(IndexedBiNode)
+		if !ok || node.Collapsed || indexed.GetIndex() < 0 {
+			continue
+		}
+		i := indexed.GetIndex()
+		for len(cells) <= i {
+			cells = append(cells, nil)
+		}
+		cells[i] = node
+	}
+	if cells == nil {
+		return nil
+	}
+
+	size := float64(opt.NodeRadius * 2)
+	top := float64(stats.MaxY) + float64(opt.NodeRadius) + arrayGap
+	if opt.SortedAxis {
+		top = axisY(stats, opt) + axisLabelGap + axisLabelTextSize*defaultLineHeight + arrayGap
+	}
+	return &arrayView{
+		cells: cells,
+		left:  float64(root.X) - size*float64(len(cells))/2,
+		top:   top,
+		size:  size,
+	}
+}
+
+// extendArrayBounds extends b to contain the array view a and its indices.
+func extendArrayBounds(b *bounds, a *arrayView) {
+	right := a.left + a.size*float64(len(a.cells))
+	bottom := a.top + a.size + arrayIndexGap + arrayIndexTextSize*defaultLineHeight
+	// half of the stroke of cells is outside
+	b.extend(a.left-0.5, a.top-0.5, right+0.5, bottom)
+}
+
+// addArrayView renders the array view a in the coordinates of layout: a row of cells with the labels of nodes,
+// the indices below the cells, and a dashed connector from every node down to its cell.
+func (sr *SvgRenderer) addArrayView(a *arrayView, opt *RenderOption) {
+	var cellAttrs, indexAttrs, connectorAttrs []svgAttribute
+	if opt.CSSClasses {
+		cellAttrs = []svgAttribute{{key: "class", value: classArrayCell}}
+		indexAttrs = []svgAttribute{{key: "class", value: classArrayIndex}}
+		connectorAttrs = []svgAttribute{{key: "class", value: classArrayConnector}}
+	} else {
+		cellAttrs = []svgAttribute{styleAttr([]svgStyleAttribute{
+			{key: "fill", value: "none"},
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: "1"},
+		})}
+		styles := []svgStyleAttribute{
+			{key: "text-anchor", value: "middle"},
+			{key: "font-size", value: strconv.Itoa(arrayIndexTextSize)},
+		}
+		if opt.NodeFieldFontFamily != "" {
+			styles = append(styles, svgStyleAttribute{key: "font-family", value: opt.NodeFieldFontFamily})
+		}
+		styles = append(styles, svgStyleAttribute{key: "fill", value: opt.EdgeLineColor})
+		indexAttrs = []svgAttribute{styleAttr(styles)}
+		connectorAttrs = []svgAttribute{styleAttr([]svgStyleAttribute{
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: "1"},
+			{key: "stroke-dasharray", value: "2 3"},
+			{key: "opacity", value: strconv.FormatFloat(levelGuideOpacity*2, 'f', 3, 64)},
+		})}
+	}
+
+	r := float64(opt.NodeRadius)
+	for i, node := range a.cells {
+		x := a.left + a.size*float64(i)
+		centre := x + a.size/2
+		if node != nil {
+			sr.constructLine(float64(node.X), float64(node.Y)+r, centre, a.top, connectorAttrs)
+		}
+		sr.writeCustomShape("rect", cellAttrs, []svgAttribute{
+			numAttr("x", x),
+			numAttr("y", a.top),
+			numAttr("width", a.size),
+			numAttr("height", a.size),
+		}, tagEndSelfClosing)
+		if node != nil {
+			sr.addText(float32(centre), float32(a.top+a.size/2), node.Field, "", opt, false)
+		}
+		y := a.top + a.size + arrayIndexGap + arrayIndexTextSize
+		sr.constructText(float32(centre), float32(y), []string{strconv.Itoa(i)}, 0, 0, "", indexAttrs)
+	}
+}
@@ -0,0 +1,93 @@
+package bitreevis
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// axisGap is the gap between the lowest node and the sorted axis.
+	axisGap = 16
+	// axisLabelTextSize is the font size of labels on the sorted axis.
+	axisLabelTextSize = 12
+	// axisLabelGap is the gap between the sorted axis and its labels.
+	axisLabelGap = 4
+)
+
+// axisLabel returns the label of node on the sorted axis, which is the first line of its text.
+// Placeholders are not on the axis.
+func axisLabel(node *PlaceableNode) string {
+	if node.Collapsed {
+		return ""
+	}
+	label, _, _ := strings.Cut(node.Field, "\n")
+	return label
+}
+
+// axisY returns the vertical position of the sorted axis below the tree described by stats.
+func axisY(stats *SizeLimitStat, opt *RenderOption) float64 {
+	return float64(stats.MaxY) + float64(opt.NodeRadius) + axisGap
+}
+
+// extendAxisBounds extends b to contain the sorted axis below nodes and its labels.
+// If horizontal is false, only the height is extended, so that the root stays at the centre.
+func extendAxisBounds(b *bounds, nodes []*PlaceableNode, stats *SizeLimitStat, horizontal bool, opt *RenderOption) {
+	y := axisY(stats, opt)
+	b.maxY = math.Max(b.maxY, y+axisLabelGap+axisLabelTextSize*defaultLineHeight)
+	if !horizontal {
+		return
+	}
+	measurer := opt.textMeasurer()
+	font := Font{Family: opt.NodeFieldFontFamily, Size: axisLabelTextSize}
+	for _, node := range nodes {
+		if label := axisLabel(node); label != "" {
+			w := measurer.MeasureString(label, font)
+			b.extend(float64(node.X)-w/2, y, float64(node.X)+w/2, y)
+		}
+	}
+}
+
+// addSortedAxis renders the sorted axis below nodes in the coordinates of layout, with a dotted projection
+// line from every node down to its label on the axis.
+func (sr *SvgRenderer) addSortedAxis(nodes []*PlaceableNode, stats *SizeLimitStat, opt *RenderOption) {
+	y := axisY(stats, opt)
+	r := float64(opt.NodeRadius)
+	var axisAttrs, projectionAttrs, labelAttrs []svgAttribute
+	if opt.CSSClasses {
+		axisAttrs = []svgAttribute{{key: "class", value: classAxis}}
+		projectionAttrs = []svgAttribute{{key: "class", value: classProjection}}
+		labelAttrs = []svgAttribute{{key: "class", value: classAxisLabel}}
+	} else {
+		axisAttrs = []svgAttribute{styleAttr([]svgStyleAttribute{
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: "1"},
+		})}
+		projectionAttrs = []svgAttribute{styleAttr([]svgStyleAttribute{
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: "1"},
+			{key: "stroke-dasharray", value: "1 3"},
+			{key: "opacity", value: strconv.FormatFloat(levelGuideOpacity*2, 'f', 3, 64)},
+		})}
+		styles := []svgStyleAttribute{
+			{key: "text-anchor", value: "middle"},
+			{key: "font-size", value: strconv.Itoa(axisLabelTextSize)},
+		}
+		if opt.NodeFieldFontFamily != "" {
+			styles = append(styles, svgStyleAttribute{key: "font-family", value: opt.NodeFieldFontFamily})
+		}
+		styles = append(styles, svgStyleAttribute{key: "fill", value: opt.EdgeLineColor})
+		labelAttrs = []svgAttribute{styleAttr(styles)}
+	}
+
+	sr.constructLine(float64(stats.MinX)-r, y, float64(stats.MaxX)+r, y, axisAttrs)
+	for _, node := range nodes {
+		label := axisLabel(node)
+		if label == "" {
+			continue
+		}
+		x := float64(node.X)
+		sr.constructLine(x, float64(node.Y)+r, x, y, projectionAttrs)
+		sr.constructText(float32(x), float32(y+axisLabelGap+axisLabelTextSize), []string{label}, 0, 0, "", labelAttrs)
+	}
+}
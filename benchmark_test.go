@@ -0,0 +1,60 @@
+package bitreevis_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+// perNodeBenchmark runs fn b.N times and reports the time and allocations spent on each node.
+func perNodeBenchmark(b *testing.B, nodes int, fn func()) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		fn()
+	}
+	elapsed := time.Since(start)
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+
+	total := float64(b.N) * float64(nodes)
+	b.ReportMetric(float64(elapsed.Nanoseconds())/total, "ns/node")
+	b.ReportMetric(float64(after.Mallocs-before.Mallocs)/total, "allocs/node")
+	b.ReportMetric(float64(after.TotalAlloc-before.TotalAlloc)/total, "B/node")
+}
+
+const benchmarkTreeHeight = 20
+
+func BenchmarkConvert_Complete(b *testing.B) {
+	root := newCompleteTreeForTest(benchmarkTreeHeight)
+	perNodeBenchmark(b, 1<<benchmarkTreeHeight-1, func() {
+		bitreevis.NewPlaceableTreeFromBiNode(root)
+	})
+}
+
+func BenchmarkLayout_Complete(b *testing.B) {
+	pRoot := bitreevis.NewPlaceableTreeFromBiNode(newCompleteTreeForTest(benchmarkTreeHeight))
+	perNodeBenchmark(b, 1<<benchmarkTreeHeight-1, func() {
+		bitreevis.PerformLayout(pRoot, 10, 10, 10)
+	})
+}
+
+func BenchmarkLayout_Chain(b *testing.B) {
+	pRoot := bitreevis.NewPlaceableTreeFromBiNode(newChainForTest(deepChainLength, true))
+	perNodeBenchmark(b, deepChainLength, func() {
+		bitreevis.PerformLayout(pRoot, 10, 10, 10)
+	})
+}
+
+func BenchmarkRender_Complete(b *testing.B) {
+	opt := &bitreevis.RenderOption{SiblingSeparation: 10, LevelSeparation: 10, NodeRadius: 10, EdgeWithArrow: true}
+	pRoot := bitreevis.NewPlaceableTreeFromBiNode(newCompleteTreeForTest(benchmarkTreeHeight))
+	pRoot = bitreevis.LayoutWithOption(pRoot, opt)
+	perNodeBenchmark(b, 1<<benchmarkTreeHeight-1, func() {
+		bitreevis.NewSvgRenderer().Render(pRoot, opt)
+	})
+}
@@ -18,14 +18,28 @@ type BiNode interface {
 	GetRightChild() BiNode
 }
 
+// NilableBiNode represents a node which reports whether it is nil itself.
+// You can implement this interface to let bitreevis check your nodes without reflect, which is faster for
+// large trees, or to make sentinel nodes, such as the nil leaves of a red-black tree, count as nil.
+type NilableBiNode interface {
+	BiNode
+
+	// IsNil reports whether the node is nil. It is called on nil pointers too.
+	IsNil() bool
+}
+
 // BiNodeIsNil reports whether the BiNode interface is nil.
 //
 // If BiNode interface itself is nil, BiNodeIsNil returns true.
+// If node implements NilableBiNode, BiNodeIsNil returns node.IsNil().
 // If BiNode interface itself is not nil, but the data of interface is nil, BiNodeIsNil returns true
 func BiNodeIsNil(node BiNode) bool {
 	if node == nil {
 		return true
 	}
+	if n, ok := node.(NilableBiNode); ok {
+		return n.IsNil()
+	}
 	// we still need to check the data underneath
 	v := reflect.ValueOf(node)
 	if v.Kind() == reflect.Ptr {
@@ -34,6 +48,22 @@ func BiNodeIsNil(node BiNode) bool {
 	return false
 }
 
+// sameBiNode reports whether a and b are the same node. Nodes of types which cannot be compared with ==,
+// such as structs holding slices, are never the same, instead of making == panic.
+func sameBiNode(a, b BiNode) bool {
+	ta := reflect.TypeOf(a)
+	return ta == reflect.TypeOf(b) && ta.Comparable() && a == b
+}
+
+// biNodeIsNil is the version of BiNodeIsNil used in hot paths, it reports the same result.
+// Trees of PlaceableNode and of NilableBiNode skip reflect.
+func biNodeIsNil(node BiNode) bool {
+	if p, ok := node.(*PlaceableNode); ok {
+		return p == nil
+	}
+	return BiNodeIsNil(node)
+}
+
 // PaintableBiNode represents a node whose color is private.
 // You can implement this interface if you want each of your node to have different colors.
 type PaintableBiNode interface {
@@ -43,6 +73,68 @@ type PaintableBiNode interface {
 	GetColor() string
 }
 
+// StyledBiNode represents a node with user-defined css classes.
+// The classes are added to the elements of the node when RenderOption.CSSClasses is set,
+// and can be styled with RenderOption.CSSExtra.
+type StyledBiNode interface {
+	BiNode
+
+	// GetClasses returns the css classes of this node.
+	GetClasses() []string
+}
+
+// IdentifiableBiNode represents a node with a user-supplied key, which is used in the ids of its svg elements
+// instead of its traversal path when RenderOption.ElementIDs is set. Keys should be unique in the tree.
+type IdentifiableBiNode interface {
+	BiNode
+
+	// GetID returns the key of this node.
+	GetID() string
+}
+
+// IndexedBiNode represents a node stored at an index of an array, such as the nodes made by FromHeapSlice.
+// RenderOption.ArrayView draws the array below the tree.
+type IndexedBiNode interface {
+	BiNode
+
+	// GetIndex returns the index of this node in the array.
+	GetIndex() int
+}
+
+// AttributedBiNode represents a node with custom data, which is rendered as data-* attributes of its svg group.
+type AttributedBiNode interface {
+	BiNode
+
+	// GetData returns the data of this node, every key k is rendered as attribute data-k.
+	// Keys are lowered and characters not allowed in attribute names are replaced by '-'. Keys which become
+	// the same name are suffixed with "-2", "-3" and so on, in the order of the original keys.
+	GetData() map[string]string
+}
+
+// LinkedBiNode represents a node with a hyperlink, its svg group is wrapped in an <a> element.
+type LinkedBiNode interface {
+	BiNode
+
+	// GetLink returns the url of the hyperlink, no hyperlink is rendered if it is empty.
+	GetLink() string
+}
+
+// DescribedBiNode represents a node with a description, which is shown as a tooltip when hovering over the node.
+type DescribedBiNode interface {
+	BiNode
+
+	// GetDescription returns the description of this node.
+	GetDescription() string
+}
+
+// EdgeLabeledBiNode represents a node with a label on the edge from its parent.
+type EdgeLabeledBiNode interface {
+	BiNode
+
+	// GetEdgeLabel returns the label of the edge from the parent to this node, no label is rendered if it is empty.
+	GetEdgeLabel() string
+}
+
 // isPaintable helps check the input data of BiNode has a method called 'GetColor'
 // If 'GetColor' method exists
 func isPaintable(root BiNode) (string, bool) {
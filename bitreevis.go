@@ -2,11 +2,22 @@ package bitreevis
 
 // VisAsSvg visualize the binary tree with given root in a svg graphic.
 // The svg graphic is saved with the given filename.
+//
+// If opt is nil, the default option is used. If opt is invalid, an error matching ErrInvalidOption is returned.
+// If root is nil, an empty tree is rendered.
 func VisAsSvg(root BiNode, filename string, opt *RenderOption) error {
+	if err := opt.Validate(); err != nil {
+		return err
+	}
+	opt = opt.WithDefaults()
+
 	// convert into inner placeable node
-	pRoot := NewPlaceableTreeFromBiNode(root)
+	pRoot := NewPlaceableTreeFromBiNodeWithOption(root, opt)
+	if opt.NodeFitText {
+		opt.NodeRadius = FitNodeRadius(pRoot, opt)
+	}
 	// perform layout
-	pRoot = PerformLayout(pRoot, opt.SiblingSeparation, opt.NodeRadius, opt.LevelSeparation)
+	pRoot = LayoutWithOption(pRoot, opt)
 	// do rendering
 	renderer := NewSvgRenderer()
 
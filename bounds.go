@@ -0,0 +1,119 @@
+package bitreevis
+
+import "math"
+
+// bounds is a rectangle in the coordinates of layout.
+type bounds struct {
+	minX, minY, maxX, maxY float64
+}
+
+// emptyBounds returns bounds which contain nothing, extending them with a rectangle gives the rectangle.
+func emptyBounds() bounds {
+	return bounds{minX: math.Inf(1), minY: math.Inf(1), maxX: math.Inf(-1), maxY: math.Inf(-1)}
+}
+
+// extend extends b to contain the rectangle from (x1, y1) to (x2, y2).
+func (b *bounds) extend(x1, y1, x2, y2 float64) {
+	b.minX = math.Min(b.minX, x1)
+	b.minY = math.Min(b.minY, y1)
+	b.maxX = math.Max(b.maxX, x2)
+	b.maxY = math.Max(b.maxY, y2)
+}
+
+// extendAround extends b to contain the square of half size d centred at (x, y).
+func (b *bounds) extendAround(x, y, d float64) {
+	b.extend(x-d, y-d, x+d, y+d)
+}
+
+func (b bounds) width() float64 {
+	return b.maxX - b.minX
+}
+
+func (b bounds) height() float64 {
+	return b.maxY - b.minY
+}
+
+// centredBounds returns the extents of nodes of radius in the tree described by stats, widened on one side
+// so that the root at x=0 is at the centre.
+func centredBounds(stats *SizeLimitStat, radius float64) bounds {
+	halfWidth := math.Max(math.Abs(float64(stats.MinX)), math.Abs(float64(stats.MaxX))) + radius
+	return bounds{
+		minX: -halfWidth,
+		minY: float64(stats.MinY) - radius,
+		maxX: halfWidth,
+		maxY: float64(stats.MaxY) + radius,
+	}
+}
+
+// tightBounds returns the true extents of the drawing of nodes of the tree rooted at root: the nodes with
+// their strokes, the labels which are wider than the nodes, the edges with their arrows and the edge labels.
+func tightBounds(root *PlaceableNode, nodes []*PlaceableNode, opt *RenderOption) bounds {
+	b := emptyBounds()
+	r := float64(opt.NodeRadius)
+	measurer := opt.textMeasurer()
+	edgeFont := Font{Size: float64(opt.EdgeLabelTextSize)}
+	offsetEnd := edgeOffsetEnd(opt)
+	var linesBuf [4]string
+	for _, node := range nodes {
+		x, y := float64(node.X), float64(node.Y)
+		b.extendAround(x, y, r+nodeStrokeWidth(node, opt)/2)
+
+		text, textY := node.Field, y
+		if node.Collapsed {
+			text, textY = placeholderLabel(node), y+r/3
+		}
+		if text != "" {
+			block := layoutNodeField(linesBuf[:0], text, opt)
+			top := textY + block.firstDy - measurer.Metrics(opt.nodeFieldFont()).CapHeight
+			b.extend(x-block.width/2, top, x+block.width/2, top+block.height)
+		}
+
+		for side, child := range []*PlaceableNode{node.Left, node.Right} {
+			if child == nil {
+				continue
+			}
+			route := routeEdge(opt.EdgeRouting, node, child, root, r, offsetEnd)
+			halfLine := float64(opt.EdgeLineWidth) / 2
+			b.extendAround(route.startX, route.startY, halfLine)
+			b.extendAround(route.endX, route.endY, math.Max(halfLine, offsetEnd))
+			if route.arcRadius != 0 {
+				for i := 0; i <= arcBoundsSamples; i++ {
+					x, y := route.arcPoint(float64(i) / arcBoundsSamples)
+					b.extendAround(x, y, halfLine)
+				}
+			}
+			label := edgeLabel(child)
+			if label == "" {
+				continue
+			}
+			lx, ly, anchor := edgeLabelPosition(opt.EdgeRouting, route, edgeSide(side), opt)
+			w := measurer.MeasureString(label, edgeFont)
+			switch anchor {
+			case "start":
+				b.extend(lx, ly-edgeFont.Size, lx+w, ly+edgeFont.Size/3)
+			case "end":
+				b.extend(lx-w, ly-edgeFont.Size, lx, ly+edgeFont.Size/3)
+			default:
+				b.extend(lx-w/2, ly-edgeFont.Size, lx+w/2, ly+edgeFont.Size/3)
+			}
+		}
+	}
+	return b
+}
+
+// arcBoundsSamples is the number of pieces an arc edge is divided into when measuring its extents.
+const arcBoundsSamples = 8
+
+// nodeStrokeWidth returns the width of the stroke around node, zero if there is none.
+func nodeStrokeWidth(node *PlaceableNode, opt *RenderOption) float64 {
+	if opt.Highlight != nil && node.Highlighted {
+		return float64(opt.highlightStrokeWidth())
+	}
+	if opt.NodeStrokeColor == "" {
+		return 0
+	}
+	if opt.NodeStrokeWidth != 0 {
+		return float64(opt.NodeStrokeWidth)
+	}
+	return DefaultNodeStrokeWidth
+}
@@ -0,0 +1,57 @@
+package bitreevis_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+func TestCollectNodesWithStat_FromFirstNode(t *testing.T) {
+	root := &bitreevis.PlaceableNode{X: 5, Y: 10, Right: &bitreevis.PlaceableNode{X: 8, Y: 20}}
+	_, stats := root.CollectNodesWithStat()
+	require.Equal(t, bitreevis.SizeLimitStat{MinX: 5, MaxX: 8, MinY: 10, MaxY: 20}, *stats)
+
+	_, stats = (*bitreevis.PlaceableNode)(nil).CollectNodesWithStat()
+	require.Equal(t, bitreevis.SizeLimitStat{}, *stats)
+}
+
+func TestSvgRenderer_TightBounds(t *testing.T) {
+	// a lopsided tree wastes the left half of the canvas when the root is centred
+	root := &myNode{Value: 1, Right: &myNode{Value: 2, Right: &myNode{Value: 3, Right: &myNode{Value: 4}}}}
+	centredW, centredH := svgSize(t, renderForTest(t, root, nil))
+	content := renderForTest(t, root, &bitreevis.RenderOption{TightBounds: true})
+	requireWellFormed(t, content)
+	w, h := svgSize(t, content)
+	require.Less(t, w, centredW*3/4)
+	require.Equal(t, centredH, h)
+
+	// a symmetric tree is as large in both modes
+	root = &myNode{Value: 2, Left: &myNode{Value: 1}, Right: &myNode{Value: 3}}
+	centredW, _ = svgSize(t, renderForTest(t, root, nil))
+	w, _ = svgSize(t, renderForTest(t, root, &bitreevis.RenderOption{TightBounds: true}))
+	require.InDelta(t, centredW, w, 1)
+
+	// labels of placeholders are wider than placeholders
+	centredW, _ = svgSize(t, renderForTest(t, root, &bitreevis.RenderOption{MaxNodes: 1}))
+	w, _ = svgSize(t, renderForTest(t, root, &bitreevis.RenderOption{MaxNodes: 1, TightBounds: true}))
+	require.Greater(t, w, centredW)
+}
+
+func TestSvgRenderer_TightBoundsWithLabels(t *testing.T) {
+	opt := &bitreevis.RenderOption{TightBounds: true}
+	plainW, _ := svgSize(t, renderForTest(t, &trieNode{Key: "r", Right: &trieNode{Key: "b"}}, opt))
+
+	// the edge label sticks out on the right
+	bit := strings.Repeat("1", 20)
+	w, _ := svgSize(t, renderForTest(t, &trieNode{Key: "r", Right: &trieNode{Key: "b", Bit: bit}}, opt))
+	measured := bitreevis.DefaultTextMeasurer.MeasureString(bit, bitreevis.Font{Size: bitreevis.DefaultEdgeLabelTextSize})
+	require.Greater(t, float64(w), float64(plainW)+measured/2)
+
+	// the label of the node is wider than the node
+	key := strings.Repeat("k", 20)
+	w, _ = svgSize(t, renderForTest(t, &trieNode{Key: "r", Right: &trieNode{Key: key}}, opt))
+	require.Greater(t, w, plainW+20)
+}
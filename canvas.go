@@ -0,0 +1,41 @@
+package bitreevis
+
+import (
+	"fmt"
+	"math"
+)
+
+// scaledCanvasSize returns the size of the graphic for a drawing of width and height according to opt.
+func scaledCanvasSize(width, height int, opt *RenderOption) (int, int) {
+	if opt.CanvasWidth > 0 && opt.CanvasHeight > 0 {
+		return opt.CanvasWidth, opt.CanvasHeight
+	}
+	w, h := float64(width), float64(height)
+	scale := 1.0
+	switch {
+	case opt.CanvasWidth > 0:
+		scale = float64(opt.CanvasWidth) / w
+	case opt.CanvasHeight > 0:
+		scale = float64(opt.CanvasHeight) / h
+	}
+	if opt.MaxCanvasWidth > 0 && w*scale > float64(opt.MaxCanvasWidth) {
+		scale = float64(opt.MaxCanvasWidth) / w
+	}
+	if opt.MaxCanvasHeight > 0 && h*scale > float64(opt.MaxCanvasHeight) {
+		scale = float64(opt.MaxCanvasHeight) / h
+	}
+	return int(math.Round(w * scale)), int(math.Round(h * scale))
+}
+
+// startCanvas starts the svg document of a drawing of width and height, with a viewBox so that
+// the drawing scales to the size of the graphic.
+func (sr *SvgRenderer) startCanvas(width, height int, opt *RenderOption) {
+	attrs := []string{fmt.Sprintf(`viewBox="0 0 %d %d"`, width, height)}
+	attrs = append(attrs, rootAttributes(sr.docTitle, sr.docDesc, opt)...)
+	if opt.Responsive {
+		sr.Canvas.Startraw(append([]string{`width="100%"`}, attrs...)...)
+		return
+	}
+	w, h := scaledCanvasSize(width, height, opt)
+	sr.Canvas.Start(w, h, attrs...)
+}
@@ -0,0 +1,77 @@
+package bitreevis_test
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+// svgViewBox returns the width and height of the viewBox of the svg document in content.
+func svgViewBox(t *testing.T, content string) (int, int) {
+	m := regexp.MustCompile(`viewBox="0 0 (\d+) (\d+)"`).FindStringSubmatch(content)
+	require.NotNil(t, m)
+	w, _ := strconv.Atoi(m[1])
+	h, _ := strconv.Atoi(m[2])
+	return w, h
+}
+
+func TestSvgRenderer_ViewBox(t *testing.T) {
+	content := renderForTest(t, newBstForTest(), nil)
+	w, h := svgSize(t, content)
+	vw, vh := svgViewBox(t, content)
+	require.Equal(t, w, vw)
+	require.Equal(t, h, vh)
+
+	content = renderForTest(t, nil, nil)
+	requireWellFormed(t, content)
+	svgViewBox(t, content)
+}
+
+func TestSvgRenderer_CanvasSize(t *testing.T) {
+	vw, vh := svgViewBox(t, renderForTest(t, newBstForTest(), nil))
+
+	content := renderForTest(t, newBstForTest(), &bitreevis.RenderOption{CanvasWidth: vw * 2})
+	requireWellFormed(t, content)
+	w, h := svgSize(t, content)
+	require.Equal(t, vw*2, w)
+	require.Equal(t, vh*2, h)
+	// the drawing itself is not changed
+	bw, bh := svgViewBox(t, content)
+	require.Equal(t, vw, bw)
+	require.Equal(t, vh, bh)
+
+	w, h = svgSize(t, renderForTest(t, newBstForTest(), &bitreevis.RenderOption{CanvasHeight: vh / 2}))
+	require.Equal(t, vh/2, h)
+	require.InDelta(t, vw/2, w, 1)
+
+	w, h = svgSize(t, renderForTest(t, newBstForTest(), &bitreevis.RenderOption{CanvasWidth: 300, CanvasHeight: 100}))
+	require.Equal(t, 300, w)
+	require.Equal(t, 100, h)
+
+	// maximum dimensions only scale down
+	w, h = svgSize(t, renderForTest(t, newBstForTest(), &bitreevis.RenderOption{MaxCanvasWidth: vw * 2, MaxCanvasHeight: vh * 2}))
+	require.Equal(t, vw, w)
+	require.Equal(t, vh, h)
+	w, h = svgSize(t, renderForTest(t, newBstForTest(), &bitreevis.RenderOption{MaxCanvasWidth: vw * 2, MaxCanvasHeight: vh / 2}))
+	require.Equal(t, vh/2, h)
+	require.InDelta(t, vw/2, w, 1)
+	w, _ = svgSize(t, renderForTest(t, newBstForTest(), &bitreevis.RenderOption{CanvasWidth: vw * 2, MaxCanvasWidth: vw}))
+	require.Equal(t, vw, w)
+
+	err := (&bitreevis.RenderOption{MaxCanvasHeight: -1}).Validate()
+	require.True(t, errors.Is(err, bitreevis.ErrInvalidOption))
+}
+
+func TestSvgRenderer_Responsive(t *testing.T) {
+	content := renderForTest(t, newBstForTest(), &bitreevis.RenderOption{Responsive: true, CanvasWidth: 100})
+	requireWellFormed(t, content)
+	svg := regexp.MustCompile(`<svg[^>]*>`).FindString(content)
+	require.Contains(t, svg, `width="100%"`)
+	require.NotContains(t, svg, "height=")
+	svgViewBox(t, content)
+}
@@ -0,0 +1,222 @@
+package bitreevis
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Classes of svg elements when RenderOption.CSSClasses is set.
+const (
+	classBackground     = "background"
+	classNode           = "node"
+	classLeaf           = "leaf"
+	classPlaceholder    = "placeholder"
+	classLabel          = "label"
+	classEdge           = "edge"
+	classEdgeLeft       = "edge-left"
+	classEdgeRight      = "edge-right"
+	classEdgeLabel      = "edge-label"
+	classArrow          = "arrow"
+	classLegend         = "legend"
+	classTitle          = "title"
+	classSubtitle       = "subtitle"
+	classCaption        = "caption"
+	classLevelGuide     = "level-guide"
+	classLevelLabel     = "level-label"
+	classRingGuide      = "ring-guide"
+	classAxis           = "axis"
+	classProjection     = "projection"
+	classAxisLabel      = "axis-label"
+	classArrayCell      = "array-cell"
+	classArrayIndex     = "array-index"
+	classArrayConnector = "array-connector"
+	classHighlighted    = "highlighted"
+	classDimmed         = "dimmed"
+)
+
+// cssRule is a css rule made of a selector and declarations.
+type cssRule struct {
+	selector     string
+	declarations []svgStyleAttribute
+}
+
+// buildStyleSheet returns the css rules defining the classes of elements styled by opt.
+// The rules changed by opt.CSSDarkTheme are added in a prefers-color-scheme media query.
+func buildStyleSheet(opt *RenderOption) string {
+	b := make([]byte, 0, 1024)
+	rules := buildCSSRules(opt)
+	b = appendCSSRules(b, rules, "")
+	if opt.CSSDarkTheme != nil {
+		dark := *opt
+		opt.CSSDarkTheme.Apply(&dark)
+		var changed []cssRule
+		for i, rule := range buildCSSRules(&dark) {
+			if !reflect.DeepEqual(rule, rules[i]) {
+				changed = append(changed, rule)
+			}
+		}
+		b = append(b, "@media (prefers-color-scheme: dark) {\n"...)
+		b = appendCSSRules(b, changed, "  ")
+		b = append(b, "}\n"...)
+	}
+	if opt.CSSExtra != "" {
+		b = append(b, opt.CSSExtra...)
+		b = append(b, '\n')
+	}
+	return string(b)
+}
+
+func buildCSSRules(opt *RenderOption) []cssRule {
+	font := opt.nodeFieldFont()
+	nodeStroke := []svgStyleAttribute{}
+	if opt.NodeStrokeColor != "" {
+		nodeStroke = append(nodeStroke,
+			svgStyleAttribute{key: "stroke", value: opt.NodeStrokeColor},
+			svgStyleAttribute{key: "stroke-width", value: strconv.Itoa(opt.NodeStrokeWidth)},
+		)
+	}
+	label := []svgStyleAttribute{
+		{key: "text-anchor", value: "middle"},
+		{key: "font-size", value: strconv.Itoa(int(font.Size)) + "px"},
+	}
+	if font.Family != "" {
+		label = append(label, svgStyleAttribute{key: "font-family", value: font.Family})
+	}
+	if font.Weight != "" {
+		label = append(label, svgStyleAttribute{key: "font-weight", value: font.Weight})
+	}
+	if font.Style != "" {
+		label = append(label, svgStyleAttribute{key: "font-style", value: font.Style})
+	}
+	label = append(label, svgStyleAttribute{key: "fill", value: opt.NodeFieldTextColor})
+	// texts around the tree share the font family of node text
+	frameText := func(size int, decls ...svgStyleAttribute) []svgStyleAttribute {
+		d := []svgStyleAttribute{{key: "font-size", value: strconv.Itoa(size) + "px"}}
+		if font.Family != "" {
+			d = append(d, svgStyleAttribute{key: "font-family", value: font.Family})
+		}
+		d = append(d, decls...)
+		return append(d, svgStyleAttribute{key: "fill", value: opt.EdgeLineColor})
+	}
+	highlight := []svgStyleAttribute{
+		{key: "stroke", value: opt.highlightColor()},
+		{key: "stroke-width", value: strconv.Itoa(opt.highlightStrokeWidth())},
+	}
+
+	return []cssRule{
+		{"." + classBackground, []svgStyleAttribute{{key: "fill", value: opt.BackgroundColor}}},
+		{"." + classNode, append([]svgStyleAttribute{{key: "fill", value: opt.NodeColor}}, nodeStroke...)},
+		{"." + classLeaf, []svgStyleAttribute{{key: "fill", value: opt.NodeLeafColor}}},
+		{"." + classPlaceholder, append([]svgStyleAttribute{{key: "fill", value: opt.PlaceholderColor}}, nodeStroke...)},
+		{"." + classLabel, label},
+		{"." + classLegend, frameText(legendTextSize)},
+		{"." + classTitle, frameText(titleTextSize, svgStyleAttribute{key: "font-weight", value: "bold"})},
+		{"." + classSubtitle, frameText(subtitleTextSize)},
+		{"." + classCaption, frameText(captionTextSize)},
+		{"." + classLevelGuide, []svgStyleAttribute{
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: "1"},
+			{key: "stroke-dasharray", value: "4 4"},
+			{key: "opacity", value: strconv.FormatFloat(levelGuideOpacity, 'f', 3, 64)},
+		}},
+		{"." + classLevelLabel, frameText(levelLabelTextSize)},
+		{"." + classRingGuide, []svgStyleAttribute{
+			{key: "fill", value: "none"},
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: "1"},
+			{key: "stroke-dasharray", value: "4 4"},
+			{key: "opacity", value: strconv.FormatFloat(levelGuideOpacity, 'f', 3, 64)},
+		}},
+		{"." + classAxis, []svgStyleAttribute{
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: "1"},
+		}},
+		{"." + classProjection, []svgStyleAttribute{
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: "1"},
+			{key: "stroke-dasharray", value: "1 3"},
+			{key: "opacity", value: strconv.FormatFloat(levelGuideOpacity*2, 'f', 3, 64)},
+		}},
+		{"." + classAxisLabel, frameText(axisLabelTextSize, svgStyleAttribute{key: "text-anchor", value: "middle"})},
+		{"." + classArrayCell, []svgStyleAttribute{
+			{key: "fill", value: "none"},
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: "1"},
+		}},
+		{"." + classArrayIndex, frameText(arrayIndexTextSize, svgStyleAttribute{key: "text-anchor", value: "middle"})},
+		{"." + classArrayConnector, []svgStyleAttribute{
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: "1"},
+			{key: "stroke-dasharray", value: "2 3"},
+			{key: "opacity", value: strconv.FormatFloat(levelGuideOpacity*2, 'f', 3, 64)},
+		}},
+		{"." + classEdge, []svgStyleAttribute{
+			{key: "fill", value: "none"},
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: strconv.Itoa(opt.EdgeLineWidth)},
+		}},
+		{"." + classEdgeLabel, []svgStyleAttribute{
+			{key: "font-size", value: strconv.Itoa(opt.EdgeLabelTextSize) + "px"},
+			{key: "fill", value: opt.EdgeLineColor},
+		}},
+		{"." + classArrow, []svgStyleAttribute{{key: "fill", value: opt.EdgeLineColor}}},
+		{"." + classNode + "." + classHighlighted, highlight},
+		{"." + classEdge + "." + classHighlighted, highlight},
+		{"." + classArrow + "." + classHighlighted, []svgStyleAttribute{{key: "fill", value: opt.highlightColor()}}},
+		{"." + classDimmed, []svgStyleAttribute{{key: "opacity", value: strconv.FormatFloat(opt.dimOpacity(), 'f', 3, 64)}}},
+	}
+}
+
+// appendCSSRules appends rules to b, one rule per line prefixed by indent.
+func appendCSSRules(b []byte, rules []cssRule, indent string) []byte {
+	for _, rule := range rules {
+		b = append(b, indent...)
+		b = append(b, rule.selector...)
+		b = append(b, '{')
+		for i, d := range rule.declarations {
+			if i != 0 {
+				b = append(b, ';')
+			}
+			b = append(b, d.key...)
+			b = append(b, ':')
+			b = append(b, d.value...)
+		}
+		b = append(b, "}\n"...)
+	}
+	return b
+}
+
+// addStyleSheet writes the style element with the css rules of opt into the canvas.
+// Every "]]>" in the rules, which would end the CDATA section, is split across two sections.
+func (sr *SvgRenderer) addStyleSheet(opt *RenderOption) {
+	sr.buf.WriteString("<style><![CDATA[\n")
+	sr.buf.WriteString(strings.ReplaceAll(buildStyleSheet(opt), "]]>", "]]]]><![CDATA[>"))
+	sr.buf.WriteString("]]></style>\n")
+}
+
+// classAttr returns the class attribute of an element of node made of classes,
+// the state classes of node and the user-defined classes of node. node can be nil.
+func classAttr(node *PlaceableNode, opt *RenderOption, classes ...string) svgAttribute {
+	b := make([]byte, 0, 32)
+	for _, class := range classes {
+		if len(b) != 0 {
+			b = append(b, ' ')
+		}
+		b = append(b, class...)
+	}
+	if node != nil {
+		if opt.Highlight != nil {
+			if node.Highlighted {
+				b = append(b, " "+classHighlighted...)
+			} else {
+				b = append(b, " "+classDimmed...)
+			}
+		}
+		for _, class := range node.Classes {
+			b = append(b, ' ')
+			b = append(b, class...)
+		}
+	}
+	return svgAttribute{key: "class", value: string(b)}
+}
@@ -0,0 +1,85 @@
+package bitreevis_test
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+type styledNode struct {
+	myNode
+	classes []string
+}
+
+func (n *styledNode) GetClasses() []string {
+	return n.classes
+}
+
+func TestSvgRenderer_CSSClasses(t *testing.T) {
+	root := &styledNode{myNode: myNode{Value: 2, Left: &myNode{Value: 1}, Right: &myNode{Value: 3}}, classes: []string{"important"}}
+	content := renderForTest(t, root, &bitreevis.RenderOption{
+		CSSClasses:    true,
+		EdgeWithArrow: true,
+		CSSDarkTheme:  &bitreevis.Theme{BackgroundColor: "#101010"},
+		CSSExtra:      ".important{stroke:gold}",
+	})
+	requireWellFormed(t, content)
+	require.Equal(t, 1, strings.Count(content, "<style>"))
+	require.NotContains(t, content, "style=")
+	require.Contains(t, content, `class="node important"`)
+	require.Contains(t, content, `class="node leaf"`)
+	require.Contains(t, content, `class="label important"`)
+	require.Contains(t, content, `class="edge edge-left"`)
+	require.Contains(t, content, `class="edge edge-right"`)
+	require.Contains(t, content, `class="background"`)
+	require.Contains(t, content, "@media (prefers-color-scheme: dark) {\n  .background{fill:#101010}\n}")
+	require.Contains(t, content, ".important{stroke:gold}")
+
+	// colors of single nodes are still inlined
+	content = renderForTest(t, &rbNode{Value: 1, Color: "red"}, &bitreevis.RenderOption{CSSClasses: true})
+	require.Contains(t, content, `style="fill:red"`)
+
+	content = renderForTest(t, newBstForTest(), &bitreevis.RenderOption{CSSClasses: true, Highlight: bitreevis.HighlightSearch("9")})
+	require.Contains(t, content, `class="node highlighted"`)
+	require.Contains(t, content, `class="edge edge-right highlighted"`)
+	require.Contains(t, content, `class="edge edge-left dimmed"`)
+}
+
+func TestSvgRenderer_CSSClassesEndOfCData(t *testing.T) {
+	extra := `.important::after{content:"]]>"}`
+	content := renderForTest(t, &myNode{Value: 1}, &bitreevis.RenderOption{
+		CSSClasses: true,
+		NodeColor:  "red]]>",
+		CSSExtra:   extra,
+	})
+	requireWellFormed(t, content)
+
+	// the style sheet is read back whole
+	var style strings.Builder
+	inStyle := false
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+		switch token := token.(type) {
+		case xml.StartElement:
+			inStyle = token.Name.Local == "style"
+		case xml.EndElement:
+			inStyle = false
+		case xml.CharData:
+			if inStyle {
+				style.Write(token)
+			}
+		}
+	}
+	require.Contains(t, style.String(), extra)
+	require.Contains(t, style.String(), "fill:red]]>")
+}
@@ -0,0 +1,217 @@
+package bitreevis_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+const deepChainLength = 1_000_000
+
+// newChainForTest returns a degenerate tree of n nodes, every node has only a left child
+// or only a right child according to left.
+func newChainForTest(n int, left bool) *myNode {
+	root := &myNode{Value: 0}
+	cur := root
+	for i := 1; i < n; i++ {
+		next := &myNode{Value: i}
+		if left {
+			cur.Left = next
+		} else {
+			cur.Right = next
+		}
+		cur = next
+	}
+	return root
+}
+
+func TestDeepChain(t *testing.T) {
+	for _, left := range []bool{true, false} {
+		root := newChainForTest(deepChainLength, left)
+		require.Equal(t, deepChainLength, bitreevis.CalHeight(root))
+
+		pRoot := bitreevis.NewPlaceableTreeFromBiNode(root)
+		pRoot = bitreevis.PerformLayout(pRoot, 10, 10, 10)
+
+		nodes, stats := pRoot.CollectNodesWithStat()
+		require.Len(t, nodes, deepChainLength)
+		require.Equal(t, float32(deepChainLength-1)*30, stats.MaxY)
+
+		// the root is at x=0 and the nodes are evenly spaced along the chain
+		require.Equal(t, float32(0), pRoot.X)
+		step := nodes[1].X - nodes[0].X
+		require.Greater(t, step, float32(0))
+		for i := 1; i < len(nodes); i++ {
+			if d := nodes[i].X - nodes[i-1].X - step; d > 1e-3 || d < -1e-3 {
+				t.Fatalf("node %d is not evenly spaced: %v", i, nodes[i].X-nodes[i-1].X)
+			}
+		}
+	}
+}
+
+func TestDeepChain_Truncated(t *testing.T) {
+	root := newChainForTest(deepChainLength, false)
+	pRoot := bitreevis.NewPlaceableTreeFromBiNodeWithOption(root, &bitreevis.RenderOption{MaxDepth: 3})
+
+	nodes := pRoot.CollectNodes()
+	require.Len(t, nodes, 5)
+	require.True(t, nodes[4].Collapsed)
+	require.Equal(t, deepChainLength-4, nodes[4].HiddenNodes)
+	require.Equal(t, deepChainLength-4, nodes[4].HiddenHeight)
+}
+
+// refExtreme is an extreme node of a subtree used by refLayoutSetup.
+type refExtreme struct {
+	addr   *bitreevis.PlaceableNode
+	offset float32
+	level  int
+}
+
+// refLayoutSetup is the recursive post-order pass of the Reingold-Tilford algorithm as it was before layoutSetup
+// was made iterative. It is kept as the reference of the iterative version.
+func refLayoutSetup(root *bitreevis.PlaceableNode, level int, lmost, rmost *refExtreme, siblingSeparation, nodeWidth, levelSeparation int) {
+	if root == nil {
+		lmost.level = -1
+		rmost.level = -1
+		return
+	}
+	ll, lr, rl, rr := &refExtreme{}, &refExtreme{}, &refExtreme{}, &refExtreme{}
+	root.Y = float32(level) * float32(nodeWidth*2+levelSeparation)
+	l, r := root.Left, root.Right
+	refLayoutSetup(l, level+1, lr, ll, siblingSeparation, nodeWidth, levelSeparation)
+	refLayoutSetup(r, level+1, rr, rl, siblingSeparation, nodeWidth, levelSeparation)
+	if r == nil && l == nil {
+		*lmost = refExtreme{addr: root, level: level}
+		*rmost = refExtreme{addr: root, level: level}
+		root.Offset = 0
+		return
+	}
+
+	currSep, rootSep := float32(siblingSeparation), float32(siblingSeparation)
+	var lOffSum, rOffSum float32
+	for l != nil && r != nil {
+		if currSep < float32(siblingSeparation) {
+			rootSep += float32(siblingSeparation) - currSep
+			currSep = float32(siblingSeparation)
+		}
+		if l.Right != nil {
+			lOffSum += l.Offset
+			currSep -= l.Offset
+			l = l.Right
+		} else {
+			lOffSum -= l.Offset
+			currSep += l.Offset
+			l = l.Left
+		}
+		if r.Left != nil {
+			rOffSum -= r.Offset
+			currSep -= r.Offset
+			r = r.Left
+		} else {
+			rOffSum += r.Offset
+			currSep += r.Offset
+			r = r.Right
+		}
+	}
+	root.Offset = (rootSep + float32(nodeWidth)) / 2
+	lOffSum -= root.Offset
+	rOffSum += root.Offset
+
+	if rl.level > ll.level || root.Left == nil {
+		*lmost = *rl
+		lmost.offset += root.Offset
+	} else {
+		*lmost = *ll
+		lmost.offset -= root.Offset
+	}
+	if lr.level > rr.level || root.Right == nil {
+		*rmost = *lr
+		rmost.offset -= root.Offset
+	} else {
+		*rmost = *rr
+		rmost.offset += root.Offset
+	}
+
+	if l != nil && l != root.Left {
+		rr.addr.Thread = true
+		rr.addr.Offset = float32(math.Abs(float64(rr.offset) + float64(root.Offset) - float64(lOffSum)))
+		if (lOffSum - root.Offset) <= rr.offset {
+			rr.addr.Left = l
+		} else {
+			rr.addr.Right = l
+		}
+	} else if r != nil && r != root.Right {
+		ll.addr.Thread = true
+		ll.addr.Offset = float32(math.Abs(float64(ll.offset) - float64(root.Offset) - float64(rOffSum)))
+		if (rOffSum + root.Offset) >= ll.offset {
+			ll.addr.Right = r
+		} else {
+			ll.addr.Left = r
+		}
+	}
+}
+
+// refLayoutPetrify is the recursive pre-order pass of the Reingold-Tilford algorithm as it was before
+// layoutPetrify was made iterative.
+func refLayoutPetrify(root *bitreevis.PlaceableNode, xPos float32) {
+	if root == nil {
+		return
+	}
+	root.X = xPos
+	if root.Thread {
+		root.Thread = false
+		root.Left = nil
+		root.Right = nil
+	}
+	refLayoutPetrify(root.Left, xPos-root.Offset)
+	refLayoutPetrify(root.Right, xPos+root.Offset)
+}
+
+// refPerformLayout lays out the tree rooted at root as PerformLayout did before it was made iterative.
+func refPerformLayout(root *bitreevis.PlaceableNode, siblingSeparation, nodeWidth, levelSeparation int) *bitreevis.PlaceableNode {
+	lmost, rmost := &refExtreme{}, &refExtreme{}
+	refLayoutSetup(root, 0, lmost, rmost, siblingSeparation+nodeWidth*2, nodeWidth, levelSeparation)
+	refLayoutPetrify(root, root.X)
+	return root
+}
+
+// newRandomBstForTest returns a binary search tree of size random values.
+func newRandomBstForTest(rnd *rand.Rand, size int) *myNode {
+	root := &myNode{Value: rnd.Intn(10 * size)}
+	for i := 1; i < size; i++ {
+		value := rnd.Intn(10 * size)
+		for cur := root; ; {
+			next := &cur.Right
+			if value < cur.Value {
+				next = &cur.Left
+			}
+			if *next == nil {
+				*next = &myNode{Value: value}
+				break
+			}
+			cur = *next
+		}
+	}
+	return root
+}
+
+func TestPerformLayout_MatchesRecursive(t *testing.T) {
+	rnd := rand.New(rand.NewSource(4))
+	for i := 0; i < 200; i++ {
+		root := newRandomBstForTest(rnd, 1+rnd.Intn(300))
+		sep, width, levelSep := rnd.Intn(30), 1+rnd.Intn(30), rnd.Intn(30)
+
+		expected := refPerformLayout(bitreevis.NewPlaceableTreeFromBiNode(root), sep, width, levelSep).CollectNodes()
+		actual := bitreevis.PerformLayout(bitreevis.NewPlaceableTreeFromBiNode(root), sep, width, levelSep).CollectNodes()
+		require.Len(t, actual, len(expected))
+		for j := range expected {
+			require.Equal(t, expected[j].Field, actual[j].Field)
+			require.Equal(t, expected[j].X, actual[j].X, "tree %d node %s", i, actual[j].Field)
+			require.Equal(t, expected[j].Y, actual[j].Y, "tree %d node %s", i, actual[j].Field)
+		}
+	}
+}
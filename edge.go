@@ -0,0 +1,229 @@
+package bitreevis
+
+import (
+	"math"
+	"strconv"
+)
+
+// EdgeRouting specifies the shape of edges between parents and children.
+type EdgeRouting int
+
+const (
+	// EdgeRoutingStraight draws straight lines from the border of the parent to the border of the child.
+	EdgeRoutingStraight EdgeRouting = iota
+	// EdgeRoutingBezier draws cubic Bézier curves from the bottom of the parent to the top of the child,
+	// leaving and entering nodes vertically.
+	EdgeRoutingBezier
+	// EdgeRoutingOrthogonal draws elbow connectors made of vertical and horizontal segments
+	// from the bottom of the parent to the top of the child.
+	EdgeRoutingOrthogonal
+	// EdgeRoutingArc draws edges following the circles around the root, which suits LayoutRadial:
+	// out from the parent along its ray, along the circle halfway to the child, and out to the child along its ray.
+	EdgeRoutingArc
+)
+
+// edgeLabelGap is the gap between an edge and its label.
+const edgeLabelGap = 4
+
+// edgeRoute is the geometry of an edge.
+type edgeRoute struct {
+	// startX, startY, endX and endY are the ends of the edge.
+	startX, startY, endX, endY float64
+	// midY is the y coordinate of the horizontal segment of an orthogonal edge
+	// and of the control points of a Bézier edge.
+	midY float64
+	// arcRadius is the radius of the circle centred at (arcCentreX, arcCentreY) followed by an arc edge,
+	// from (arcX1, arcY1) to (arcX2, arcY2). arcSweep reports whether the arc goes clockwise.
+	arcRadius                  float64
+	arcCentreX, arcCentreY     float64
+	arcX1, arcY1, arcX2, arcY2 float64
+	arcSweep                   bool
+}
+
+// routeEdge returns the route of the edge from parent to child according to routing.
+// radius is the radius of nodes, offsetEnd specifies how far the edge end goes backward to leave room for an arrow.
+// centre is the centre of the circles followed by arc edges, which is the root.
+func routeEdge(routing EdgeRouting, parent, child, centre *PlaceableNode, radius, offsetEnd float64) edgeRoute {
+	x1, y1, x2, y2 := float64(parent.X), float64(parent.Y), float64(child.X), float64(child.Y)
+	if routing == EdgeRoutingArc {
+		if r, ok := routeArcEdge(parent, child, centre, radius, offsetEnd); ok {
+			return r
+		}
+		routing = EdgeRoutingStraight
+	}
+	if routing == EdgeRoutingStraight {
+		var r edgeRoute
+		r.startX, r.startY, r.endX, r.endY = measureEdgeStartEnd(x1, y1, x2, y2, radius, 0, offsetEnd)
+		r.midY = (r.startY + r.endY) / 2
+		return r
+	}
+	r := edgeRoute{startX: x1, startY: y1 + radius, endX: x2, endY: y2 - radius - offsetEnd}
+	r.midY = (r.startY + y2 - radius) / 2
+	return r
+}
+
+// routeArcEdge returns the route of the arc edge from parent to child around centre.
+// It reports false if parent is centre or child is not farther from centre than parent,
+// in which case there is no arc to follow.
+func routeArcEdge(parent, child, centre *PlaceableNode, radius, offsetEnd float64) (edgeRoute, bool) {
+	cx, cy := float64(centre.X), float64(centre.Y)
+	px, py := float64(parent.X)-cx, float64(parent.Y)-cy
+	qx, qy := float64(child.X)-cx, float64(child.Y)-cy
+	r1, r2 := math.Hypot(px, py), math.Hypot(qx, qy)
+	if r1 == 0 || r2-r1 <= 2*radius+offsetEnd {
+		return edgeRoute{}, false
+	}
+	// unit vectors along the rays of the parent and the child
+	ux1, uy1 := px/r1, py/r1
+	ux2, uy2 := qx/r2, qy/r2
+
+	mid := (r1 + r2) / 2
+	r := edgeRoute{
+		startX:     cx + ux1*(r1+radius),
+		startY:     cy + uy1*(r1+radius),
+		endX:       cx + ux2*(r2-radius-offsetEnd),
+		endY:       cy + uy2*(r2-radius-offsetEnd),
+		arcRadius:  mid,
+		arcCentreX: cx,
+		arcCentreY: cy,
+		arcX1:      cx + ux1*mid,
+		arcY1:      cy + uy1*mid,
+		arcX2:      cx + ux2*mid,
+		arcY2:      cy + uy2*mid,
+		// y goes down, so a positive cross product turns clockwise on screen
+		arcSweep: ux1*uy2-uy1*ux2 > 0,
+	}
+	r.midY = (r.startY + r.endY) / 2
+	return r, true
+}
+
+// arcPoint returns the point of the arc of route at t, which goes from 0 at the start of the arc to 1 at its end.
+func (r edgeRoute) arcPoint(t float64) (float64, float64) {
+	a1 := math.Atan2(r.arcY1-r.arcCentreY, r.arcX1-r.arcCentreX)
+	a2 := math.Atan2(r.arcY2-r.arcCentreY, r.arcX2-r.arcCentreX)
+	// the arc is the shorter one between its ends
+	delta := math.Remainder(a2-a1, 2*math.Pi)
+	a := a1 + delta*t
+	return r.arcCentreX + r.arcRadius*math.Cos(a), r.arcCentreY + r.arcRadius*math.Sin(a)
+}
+
+// appendPathData appends the path data of route drawn with routing to b.
+func appendPathData(b []byte, routing EdgeRouting, route edgeRoute) []byte {
+	b = append(b, 'M', ' ')
+	b = appendSvgNumber(b, route.startX)
+	b = append(b, ' ')
+	b = appendSvgNumber(b, route.startY)
+	switch routing {
+	case EdgeRoutingBezier:
+		b = append(b, " C "...)
+		b = appendSvgNumber(b, route.startX)
+		b = append(b, ' ')
+		b = appendSvgNumber(b, route.midY)
+		b = append(b, ' ')
+		b = appendSvgNumber(b, route.endX)
+		b = append(b, ' ')
+		b = appendSvgNumber(b, route.midY)
+		b = append(b, ' ')
+	case EdgeRoutingOrthogonal:
+		b = append(b, " V "...)
+		b = appendSvgNumber(b, route.midY)
+		b = append(b, " H "...)
+		b = appendSvgNumber(b, route.endX)
+		b = append(b, " V "...)
+		b = appendSvgNumber(b, route.endY)
+		return b
+	case EdgeRoutingArc:
+		if route.arcRadius == 0 {
+			// the edge falls back to a straight line
+			b = append(b, " L "...)
+			break
+		}
+		b = append(b, " L "...)
+		b = appendSvgNumber(b, route.arcX1)
+		b = append(b, ' ')
+		b = appendSvgNumber(b, route.arcY1)
+		b = append(b, " A "...)
+		b = appendSvgNumber(b, route.arcRadius)
+		b = append(b, ' ')
+		b = appendSvgNumber(b, route.arcRadius)
+		if route.arcSweep {
+			b = append(b, " 0 0 1 "...)
+		} else {
+			b = append(b, " 0 0 0 "...)
+		}
+		b = appendSvgNumber(b, route.arcX2)
+		b = append(b, ' ')
+		b = appendSvgNumber(b, route.arcY2)
+		b = append(b, " L "...)
+	default:
+		b = append(b, " L "...)
+	}
+	b = appendSvgNumber(b, route.endX)
+	b = append(b, ' ')
+	b = appendSvgNumber(b, route.endY)
+	return b
+}
+
+// constructEdge writes the edge of route drawn with routing into the canvas.
+func (sr *SvgRenderer) constructEdge(routing EdgeRouting, route edgeRoute, attrs []svgAttribute) {
+	if routing == EdgeRoutingStraight {
+		sr.constructLine(route.startX, route.startY, route.endX, route.endY, attrs)
+		return
+	}
+	bp := svgElementBufferPool.Get().(*[]byte)
+	d := appendPathData((*bp)[:0], routing, route)
+	sr.writeCustomShape("path", attrs, []svgAttribute{{key: "d", value: string(d)}}, tagEndSelfClosing)
+	*bp = d
+	svgElementBufferPool.Put(bp)
+}
+
+// edgeLabel returns the label of the edge from the parent to child.
+func edgeLabel(child *PlaceableNode) string {
+	if labeled, ok := child.Source.(EdgeLabeledBiNode); ok && !child.Collapsed {
+		return labeled.GetEdgeLabel()
+	}
+	return ""
+}
+
+// edgeLabelPosition returns the anchor point and the text-anchor of the label of the edge of route drawn
+// with routing, which goes to side. Labels of orthogonal edges are placed above the horizontal segment,
+// other labels are placed beside the middle of the edge, away from the edge.
+func edgeLabelPosition(routing EdgeRouting, route edgeRoute, side edgeSide, opt *RenderOption) (x, y float64, anchor string) {
+	x, y = (route.startX+route.endX)/2, route.midY+float64(opt.EdgeLabelTextSize)/3
+	anchor = "middle"
+	switch {
+	case routing == EdgeRoutingOrthogonal:
+		y = route.midY - edgeLabelGap
+	case side == edgeLeft:
+		x -= edgeLabelGap
+		anchor = "end"
+	default:
+		x += edgeLabelGap
+		anchor = "start"
+	}
+	return
+}
+
+// addEdgeLabel renders label beside the edge of route drawn with routing, which goes to side.
+func (sr *SvgRenderer) addEdgeLabel(label string, routing EdgeRouting, route edgeRoute, side edgeSide, dimmed bool, opt *RenderOption) {
+	x, y, anchor := edgeLabelPosition(routing, route, side, opt)
+	var attrs []svgAttribute
+	if opt.CSSClasses {
+		class := classEdgeLabel
+		if dimmed {
+			class += " " + classDimmed
+		}
+		attrs = []svgAttribute{{key: "class", value: class}, {key: "text-anchor", value: anchor}}
+	} else {
+		styles := []svgStyleAttribute{
+			{key: "text-anchor", value: anchor},
+			{key: "font-size", value: strconv.Itoa(opt.EdgeLabelTextSize)},
+			{key: "fill", value: opt.EdgeLineColor},
+		}
+		if dimmed {
+			styles = append(styles, svgStyleAttribute{key: "opacity", value: strconv.FormatFloat(opt.dimOpacity(), 'f', 3, 64)})
+		}
+		attrs = []svgAttribute{styleAttr(styles)}
+	}
+	sr.constructText(float32(x), float32(y), []string{label}, 0, 0, "", attrs)
+}
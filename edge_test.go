@@ -0,0 +1,61 @@
+package bitreevis_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+type trieNode struct {
+	Left, Right *trieNode
+	Key         string
+	Bit         string
+}
+
+func (n *trieNode) GetLeftChild() bitreevis.BiNode {
+	return n.Left
+}
+
+func (n *trieNode) GetRightChild() bitreevis.BiNode {
+	return n.Right
+}
+
+func (n *trieNode) GetField() string {
+	return n.Key
+}
+
+func (n *trieNode) GetEdgeLabel() string {
+	return n.Bit
+}
+
+func TestSvgRenderer_EdgeRouting(t *testing.T) {
+	root := &trieNode{Key: "r", Left: &trieNode{Key: "a", Bit: "0"}, Right: &trieNode{Key: "b", Bit: "1"}}
+	opt := &bitreevis.RenderOption{NodeRadius: 10, SiblingSeparation: 10, LevelSeparation: 20, EdgeWithArrow: true}
+
+	// the root is at (0,0), the children are at (-20,40) and (20,40)
+	opt.EdgeRouting = bitreevis.EdgeRoutingBezier
+	content := renderForTest(t, root, opt)
+	requireWellFormed(t, content)
+	require.Contains(t, content, `d="M 0.000 10.000 C 0.000 20.000 -20.000 20.000 -20.000 28.000"`)
+	require.Contains(t, content, "fill:none")
+	require.Contains(t, content, `marker-end="url(#self-defined-arrow-marker)"`)
+
+	opt.EdgeRouting = bitreevis.EdgeRoutingOrthogonal
+	content = renderForTest(t, root, opt)
+	require.Contains(t, content, `d="M 0.000 10.000 V 20.000 H 20.000 V 28.000"`)
+	// labels of orthogonal edges are above the horizontal segment
+	require.Contains(t, content, `x="10.000" y="16.000" >`+"\n"+`<tspan x="10.000" dy="0.000" >1</tspan>`)
+
+	opt.EdgeRouting = bitreevis.EdgeRoutingStraight
+	content = renderForTest(t, root, opt)
+	require.Contains(t, content, "<line ")
+	require.NotContains(t, content, "<path style")
+	require.Contains(t, content, `text-anchor:end;font-size:12`)
+	require.Contains(t, content, `>0</tspan>`)
+
+	err := (&bitreevis.RenderOption{EdgeRouting: 5}).Validate()
+	require.True(t, errors.Is(err, bitreevis.ErrInvalidOption))
+}
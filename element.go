@@ -0,0 +1,193 @@
+package bitreevis
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of elements which have ids, they are the first letter of ids.
+const (
+	elementKindNode  = "n"
+	elementKindLabel = "l"
+	elementKindEdge  = "e"
+)
+
+const (
+	// maxPathKeyLength is the length of the longest traversal path used as a key as it is. Longer paths are
+	// keyed by their first pathKeyPrefixLength steps and the hash of the whole path, so that the keys of the nodes
+	// of deep trees do not take quadratic time and memory.
+	maxPathKeyLength    = 64
+	pathKeyPrefixLength = 48
+	// fnvOffsetBasis64 and fnvPrime64 are the parameters of the 64-bit FNV-1a hash of paths.
+	fnvOffsetBasis64 = 14695981039346656037
+	fnvPrime64       = 1099511628211
+)
+
+// buildElementKeys returns the keys of element ids of every node in the tree rooted at root.
+//
+// The key of a node is the key of IdentifiableBiNode if implemented, otherwise its traversal path from root,
+// made of 'L' and 'R'. The key of root is empty unless it implements IdentifiableBiNode. A path longer than
+// 64 steps is replaced by its first 48 steps, '_' and the hash of the whole path in hex.
+// Keys are unique: a key which is already used by a node earlier in pre-order is suffixed by "-2", "-3" and so on.
+func buildElementKeys(root *PlaceableNode) map[*PlaceableNode]string {
+	type item struct {
+		node *PlaceableNode
+		// prefix is the path from root, up to maxPathKeyLength steps, length is the length of the whole path
+		// and hash is its FNV-1a hash.
+		prefix string
+		length int
+		hash   uint64
+	}
+	keys := make(map[*PlaceableNode]string)
+	used := make(map[string]bool)
+	child := func(it item, step byte) item {
+		c := item{length: it.length + 1, hash: (it.hash ^ uint64(step)) * fnvPrime64, prefix: it.prefix}
+		if it.length < maxPathKeyLength {
+			c.prefix = it.prefix + string(step)
+		}
+		return c
+	}
+	stack := []item{{node: root, hash: fnvOffsetBasis64}}
+	for len(stack) != 0 {
+		it := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		key := it.prefix
+		if it.length > maxPathKeyLength {
+			key = it.prefix[:pathKeyPrefixLength] + "_" + strconv.FormatUint(it.hash, 16)
+		}
+		if identifiable, ok := it.node.Source.(IdentifiableBiNode); ok {
+			key = sanitizeElementKey(identifiable.GetID())
+		}
+		if used[key] {
+			base := key
+			for n := 2; used[key]; n++ {
+				key = base + "-" + strconv.Itoa(n)
+			}
+		}
+		used[key] = true
+		keys[it.node] = key
+		if it.node.Right != nil {
+			r := child(it, 'R')
+			r.node = it.node.Right
+			stack = append(stack, r)
+		}
+		if it.node.Left != nil {
+			l := child(it, 'L')
+			l.node = it.node.Left
+			stack = append(stack, l)
+		}
+	}
+	return keys
+}
+
+// elementID returns the id of the element of kind with key.
+func elementID(prefix, kind, key string) string {
+	if key == "" {
+		return prefix + kind
+	}
+	return prefix + kind + "-" + key
+}
+
+// sanitizeElementKey replaces the characters which are not allowed in ids by '_'.
+func sanitizeElementKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// sanitizeDataKey makes key a valid name of data-* attribute, which consists of lower case letters,
+// digits, '-', '_' and '.'. Upper case letters are lowered and other characters are replaced by '-'.
+func sanitizeDataKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.':
+			return r
+		}
+		return '-'
+	}, key)
+}
+
+// appendDataAttrs appends a data-* attribute for every non-empty key of data to attrs, ordered by key.
+// Keys which sanitize to the same name, such as "Weight" and "weight", are suffixed with "-2", "-3" and so on
+// in the order of the original keys, so that no attribute is repeated.
+func appendDataAttrs(attrs []svgAttribute, data map[string]string) []svgAttribute {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	// keys are sorted so that the output is deterministic
+	sort.Strings(keys)
+
+	dataAttrs := make([]svgAttribute, 0, len(keys))
+	used := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		name := sanitizeDataKey(k)
+		for n := 2; used[name]; n++ {
+			name = sanitizeDataKey(k) + "-" + strconv.Itoa(n)
+		}
+		used[name] = true
+		dataAttrs = append(dataAttrs, svgAttribute{key: "data-" + name, value: data[k]})
+	}
+	// names are unique, so sorting them is deterministic as well
+	sort.Slice(dataAttrs, func(i, j int) bool { return dataAttrs[i].key < dataAttrs[j].key })
+	attrs = append(attrs, dataAttrs...)
+	return attrs
+}
+
+// beginNodeGroup opens the hyperlink and the group wrapping the elements of node, if node needs them.
+// The group carries the id, the data, the ARIA attributes and the description of node.
+// It reports which of them are opened, they should be closed by endNodeGroup.
+func (sr *SvgRenderer) beginNodeGroup(node *PlaceableNode, opt *RenderOption) (link, grouped bool) {
+	if linked, ok := node.Source.(LinkedBiNode); ok && !node.Collapsed {
+		if href := linked.GetLink(); href != "" {
+			sr.svgCanvasBeginCustomShape("a", []svgAttribute{
+				{key: "href", value: href},
+				{key: "xlink:href", value: href},
+			})
+			link = true
+		}
+	}
+
+	var attrs []svgAttribute
+	if sr.elementKeys != nil {
+		attrs = append(attrs, svgAttribute{key: "id", value: elementID(opt.ElementIDPrefix, elementKindNode, sr.elementKeys[node])})
+	}
+	if attributed, ok := node.Source.(AttributedBiNode); ok && !node.Collapsed {
+		attrs = appendDataAttrs(attrs, attributed.GetData())
+	}
+	if opt.Accessible {
+		attrs = append(attrs,
+			svgAttribute{key: "role", value: "graphics-symbol"},
+			svgAttribute{key: "aria-label", value: nodeAriaLabel(node)},
+		)
+	}
+	desc := nodeDescription(node)
+	if attrs != nil || desc != "" {
+		sr.svgCanvasBeginCustomShape("g", attrs)
+		grouped = true
+	}
+	if desc != "" {
+		// the title is the tooltip of the whole group
+		sr.constructTitle(desc)
+	}
+
+	return link, grouped
+}
+
+// endNodeGroup closes what is opened by beginNodeGroup.
+func (sr *SvgRenderer) endNodeGroup(link, grouped bool) {
+	if grouped {
+		sr.svgCanvasEndCustomShape("g")
+	}
+	if link {
+		sr.svgCanvasEndCustomShape("a")
+	}
+}
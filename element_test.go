@@ -0,0 +1,107 @@
+package bitreevis_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+type scriptableNode struct {
+	Left, Right *scriptableNode
+	Key         string
+	Data        map[string]string
+	Link        string
+}
+
+func (n *scriptableNode) GetLeftChild() bitreevis.BiNode {
+	return n.Left
+}
+
+func (n *scriptableNode) GetRightChild() bitreevis.BiNode {
+	return n.Right
+}
+
+func (n *scriptableNode) GetField() string {
+	return n.Key
+}
+
+func (n *scriptableNode) GetID() string {
+	return n.Key
+}
+
+func (n *scriptableNode) GetData() map[string]string {
+	return n.Data
+}
+
+func (n *scriptableNode) GetLink() string {
+	return n.Link
+}
+
+func TestSvgRenderer_ElementIDsFromPath(t *testing.T) {
+	content := renderForTest(t, newBstForTest(), &bitreevis.RenderOption{ElementIDs: true, ElementIDPrefix: "t1-"})
+	requireWellFormed(t, content)
+	for _, id := range []string{"t1-n", "t1-l", "t1-n-L", "t1-l-L", "t1-e-L", "t1-n-RR", "t1-n-RRR", "t1-e-RRR", "t1-l-RL"} {
+		require.Contains(t, content, `id="`+id+`"`)
+	}
+	require.NotContains(t, content, `id="t1-e"`)
+
+	// ids are deterministic
+	require.Equal(t, content, renderForTest(t, newBstForTest(), &bitreevis.RenderOption{ElementIDs: true, ElementIDPrefix: "t1-"}))
+}
+
+func TestSvgRenderer_ElementKeysDataAndLinks(t *testing.T) {
+	root := &scriptableNode{
+		Key:  "root",
+		Data: map[string]string{"Weight": "3", "size": `"big"`},
+		Link: "https://example.com/?a=1&b=2",
+		Left: &scriptableNode{Key: "a b"},
+	}
+	content := renderForTest(t, root, &bitreevis.RenderOption{ElementIDs: true})
+	requireWellFormed(t, content)
+	require.Contains(t, content, `id="n-root"`)
+	require.Contains(t, content, `id="n-a_b"`)
+	require.Contains(t, content, `id="e-a_b"`)
+	require.Contains(t, content, `data-size="&#34;big&#34;" data-weight="3"`)
+	require.Contains(t, content, `href="https://example.com/?a=1&amp;b=2"`)
+
+	// data and links are rendered without ids
+	content = renderForTest(t, root, nil)
+	require.Contains(t, content, `data-weight="3"`)
+	require.NotContains(t, content, `id="n-root"`)
+	require.Equal(t, 1, strings.Count(content, "<a "))
+}
+
+func TestSvgRenderer_DataKeysCollide(t *testing.T) {
+	root := &scriptableNode{Key: "root", Data: map[string]string{"Weight": "1", "weight": "2", "a b": "3", "a-b": "4"}}
+	for i := 0; i < 10; i++ {
+		content := renderForTest(t, root, nil)
+		requireWellFormed(t, content)
+		require.Contains(t, content, `data-a-b="3" data-a-b-2="4" data-weight="1" data-weight-2="2"`)
+		require.Equal(t, 1, strings.Count(content, "data-weight="))
+	}
+}
+
+func TestSvgRenderer_ElementIDsUnique(t *testing.T) {
+	root := &scriptableNode{Key: "k", Left: &scriptableNode{Key: "k"}, Right: &scriptableNode{Key: "k-2"}}
+	content := renderForTest(t, root, &bitreevis.RenderOption{ElementIDs: true})
+	requireWellFormed(t, content)
+	for _, id := range []string{"n-k", "n-k-2", "n-k-2-2"} {
+		require.Equal(t, 1, strings.Count(content, `id="`+id+`"`), id)
+	}
+
+	// long paths are shortened, and stay unique
+	content = renderForTest(t, newChainForTest(2000, true), &bitreevis.RenderOption{ElementIDs: true})
+	ids := regexp.MustCompile(`id="(n-[^"]*)"`).FindAllStringSubmatch(content, -1)
+	require.Len(t, ids, 1999)
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		require.LessOrEqual(t, len(id[1]), len("n-")+48+1+16, id[1])
+		require.False(t, seen[id[1]], id[1])
+		seen[id[1]] = true
+	}
+	require.True(t, seen["n-"+strings.Repeat("L", 64)])
+}
@@ -0,0 +1,519 @@
+package bitreevis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Default colors of the nodes of expression trees.
+const (
+	DefaultOperatorColor = "#f4b942"
+	DefaultOperandColor  = "#9cc3e4"
+)
+
+// ErrInvalidExpression is matched by every error returned from ParseInfix, ParsePrefix, ParsePostfix
+// and ExprNode.Evaluate, it can be checked with errors.Is.
+var ErrInvalidExpression = errors.New("bitreevis: invalid expression")
+
+// ExpressionError describes where an expression is invalid.
+type ExpressionError struct {
+	// Pos is the byte offset of Token in the expression.
+	Pos int
+	// Token is the invalid token, empty at the end of the expression.
+	Token string
+	// Reason describes why the expression is invalid.
+	Reason string
+}
+
+func (e *ExpressionError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("bitreevis: invalid expression at %d: %s", e.Pos, e.Reason)
+	}
+	return fmt.Sprintf("bitreevis: invalid expression at %d %q: %s", e.Pos, e.Token, e.Reason)
+}
+
+// Is reports whether target is ErrInvalidExpression.
+func (e *ExpressionError) Is(target error) bool {
+	return target == ErrInvalidExpression
+}
+
+// exprOperator is a binary operator of expressions.
+type exprOperator struct {
+	precedence int
+	// rightAssoc reports whether the operator groups from the right, as in 2^3^2 = 2^(3^2).
+	rightAssoc bool
+}
+
+// exprOperators are the binary operators of expressions, the higher precedence binds tighter.
+var exprOperators = map[string]exprOperator{
+	"||": {precedence: 1},
+	"&&": {precedence: 2},
+	"==": {precedence: 3},
+	"!=": {precedence: 3},
+	"<":  {precedence: 3},
+	"<=": {precedence: 3},
+	">":  {precedence: 3},
+	">=": {precedence: 3},
+	"+":  {precedence: 4},
+	"-":  {precedence: 4},
+	"*":  {precedence: 5},
+	"/":  {precedence: 5},
+	"%":  {precedence: 5},
+	"^":  {precedence: 7, rightAssoc: true},
+}
+
+// unaryPrecedence is the precedence of the unary operators of infix expressions, which bind tighter than
+// the binary operators except ^, so that -2^2 = -(2^2).
+const unaryPrecedence = 6
+
+// ExprNode is a node of an expression tree made by ParseInfix, ParsePrefix or ParsePostfix.
+//
+// Operators and operands are drawn in DefaultOperatorColor and DefaultOperandColor, and have the css class
+// operator or operand when RenderOption.CSSClasses is set.
+type ExprNode struct {
+	// Token is the operator or the operand of the node.
+	Token string
+	// Operator reports whether the node is an operator. Operands are numbers and variables, they are leaves.
+	Operator bool
+	// Left and Right are the operands of an operator. A unary operator has Right only.
+	Left, Right *ExprNode
+	// Color is the color of the node.
+	Color string
+	// Value is the value of the subtree rooted at the node, calculated by Evaluate.
+	Value float64
+	// Annotated specifies whether Value is shown below Token, it is set by Annotate.
+	Annotated bool
+
+	// pos is the byte offset of Token in the expression.
+	pos int
+}
+
+func (n *ExprNode) GetField() string {
+	if !n.Annotated {
+		return n.Token
+	}
+	return n.Token + "\n= " + strconv.FormatFloat(n.Value, 'g', -1, 64)
+}
+
+func (n *ExprNode) GetLeftChild() BiNode {
+	if n.Left == nil {
+		return nil
+	}
+	return n.Left
+}
+
+func (n *ExprNode) GetRightChild() BiNode {
+	if n.Right == nil {
+		return nil
+	}
+	return n.Right
+}
+
+func (n *ExprNode) GetColor() string {
+	return n.Color
+}
+
+func (n *ExprNode) GetClasses() []string {
+	if n.Operator {
+		return []string{"operator"}
+	}
+	return []string{"operand"}
+}
+
+// String returns the expression of the tree rooted at n in infix notation, with every operation in parentheses.
+func (n *ExprNode) String() string {
+	if !n.Operator {
+		return n.Token
+	}
+	if n.Left == nil {
+		return "(" + n.Token + n.Right.String() + ")"
+	}
+	return "(" + n.Left.String() + " " + n.Token + " " + n.Right.String() + ")"
+}
+
+// Evaluate calculates the value of the expression rooted at n, and sets Value of every node to the value
+// of its subtree. Variables take their values from vars.
+//
+// Comparisons and logical operators give 1 for true and 0 for false, and take non-zero operands as true.
+// An unknown variable or a division by zero gives an error matching ErrInvalidExpression.
+func (n *ExprNode) Evaluate(vars map[string]float64) (float64, error) {
+	if !n.Operator {
+		if v, ok := exprNumber(n.Token); ok {
+			n.Value = v
+			return v, nil
+		}
+		v, ok := vars[n.Token]
+		if !ok {
+			return 0, &ExpressionError{Pos: n.pos, Token: n.Token, Reason: "unknown variable"}
+		}
+		n.Value = v
+		return v, nil
+	}
+
+	if n.Left == nil {
+		r, err := n.Right.Evaluate(vars)
+		if err != nil {
+			return 0, err
+		}
+		switch n.Token {
+		case "-":
+			n.Value = -r
+		case "!":
+			n.Value = exprBool(r == 0)
+		}
+		return n.Value, nil
+	}
+	l, err := n.Left.Evaluate(vars)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.Right.Evaluate(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch n.Token {
+	case "||":
+		n.Value = exprBool(l != 0 || r != 0)
+	case "&&":
+		n.Value = exprBool(l != 0 && r != 0)
+	case "==":
+		n.Value = exprBool(l == r)
+	case "!=":
+		n.Value = exprBool(l != r)
+	case "<":
+		n.Value = exprBool(l < r)
+	case "<=":
+		n.Value = exprBool(l <= r)
+	case ">":
+		n.Value = exprBool(l > r)
+	case ">=":
+		n.Value = exprBool(l >= r)
+	case "+":
+		n.Value = l + r
+	case "-":
+		n.Value = l - r
+	case "*":
+		n.Value = l * r
+	case "/", "%":
+		if r == 0 {
+			return 0, &ExpressionError{Pos: n.pos, Token: n.Token, Reason: "division by zero"}
+		}
+		if n.Token == "/" {
+			n.Value = l / r
+		} else {
+			n.Value = math.Mod(l, r)
+		}
+	case "^":
+		n.Value = math.Pow(l, r)
+	}
+	return n.Value, nil
+}
+
+// Annotate evaluates the expression rooted at n as Evaluate does, and shows the value of every subtree
+// below its token. Numbers are not annotated, as their values are their tokens.
+func (n *ExprNode) Annotate(vars map[string]float64) error {
+	if _, err := n.Evaluate(vars); err != nil {
+		return err
+	}
+	n.annotate()
+	return nil
+}
+
+func (n *ExprNode) annotate() {
+	if n == nil {
+		return
+	}
+	_, number := exprNumber(n.Token)
+	n.Annotated = !number
+	n.Left.annotate()
+	n.Right.annotate()
+}
+
+// exprNumber returns the value of the operand token if it is a number. Numbers start with a digit or '.',
+// so that variables such as "inf" and "nan" are not taken for numbers.
+func exprNumber(token string) (float64, bool) {
+	if token == "" || !(token[0] >= '0' && token[0] <= '9' || token[0] == '.') {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(token, 64)
+	return v, err == nil
+}
+
+func exprBool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// exprTokenKind is the kind of a token of expressions.
+type exprTokenKind int
+
+const (
+	exprOperand exprTokenKind = iota
+	exprOperatorToken
+	exprLeftParen
+	exprRightParen
+)
+
+// exprToken is a token of an expression at byte offset pos.
+type exprToken struct {
+	kind exprTokenKind
+	text string
+	pos  int
+}
+
+// tokenizeExpression splits expr into numbers, variables, operators and parentheses.
+// Variables are made of letters, digits, '_' and '.', and start with a letter or '_'.
+func tokenizeExpression(expr string) ([]exprToken, error) {
+	var tokens []exprToken
+	isWord := func(r rune) bool { return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for i := 0; i < len(expr); {
+		c, size := utf8.DecodeRuneInString(expr[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case c == '(' || c == ')':
+			kind := exprLeftParen
+			if c == ')' {
+				kind = exprRightParen
+			}
+			tokens = append(tokens, exprToken{kind: kind, text: expr[i : i+1], pos: i})
+			i++
+		case isWord(c):
+			end := i + strings.IndexFunc(expr[i:], func(r rune) bool { return !isWord(r) })
+			if end < i {
+				end = len(expr)
+			}
+			text := expr[i:end]
+			if unicode.IsDigit(c) || c == '.' {
+				if _, ok := exprNumber(text); !ok {
+					return nil, &ExpressionError{Pos: i, Token: text, Reason: "invalid number"}
+				}
+			}
+			tokens = append(tokens, exprToken{kind: exprOperand, text: text, pos: i})
+			i = end
+		default:
+			text := ""
+			if i+2 <= len(expr) {
+				if _, ok := exprOperators[expr[i:i+2]]; ok {
+					text = expr[i : i+2]
+				}
+			}
+			if text == "" && strings.ContainsRune("+-*/%^<>!", c) {
+				text = expr[i : i+1]
+			}
+			if text == "" {
+				return nil, &ExpressionError{Pos: i, Token: expr[i : i+size], Reason: "unexpected character"}
+			}
+			tokens = append(tokens, exprToken{kind: exprOperatorToken, text: text, pos: i})
+			i += len(text)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, &ExpressionError{Reason: "empty expression"}
+	}
+	return tokens, nil
+}
+
+func newOperandNode(t exprToken) *ExprNode {
+	return &ExprNode{Token: t.text, Color: DefaultOperandColor, pos: t.pos}
+}
+
+func newOperatorNode(t exprToken, left, right *ExprNode) *ExprNode {
+	return &ExprNode{Token: t.text, Operator: true, Left: left, Right: right, Color: DefaultOperatorColor, pos: t.pos}
+}
+
+// ParseInfix parses an expression in infix notation, such as "a * (b + 2)", into an expression tree.
+//
+// The binary operators are, from the loosest to the tightest: ||, &&, the comparisons == != < <= > >=,
+// + -, * / %, and ^ which groups from the right. The unary operators - + ! bind tighter than every binary
+// operator but ^. A unary + is dropped, the other unary operators have their operand as Right.
+// Operands are numbers and variables. An invalid expression gives an error matching ErrInvalidExpression.
+func ParseInfix(expr string) (*ExprNode, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &infixParser{tokens: tokens, end: len(expr)}
+	root, err := p.parse(1)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := p.peek(); ok {
+		return nil, &ExpressionError{Pos: t.pos, Token: t.text, Reason: "unexpected token"}
+	}
+	return root, nil
+}
+
+// infixParser parses infix expressions by precedence climbing.
+type infixParser struct {
+	tokens []exprToken
+	next   int
+	// end is the length of the expression, which is the position of errors at the end.
+	end int
+}
+
+func (p *infixParser) peek() (exprToken, bool) {
+	if p.next == len(p.tokens) {
+		return exprToken{}, false
+	}
+	return p.tokens[p.next], true
+}
+
+// parse parses the expression made of the binary operators whose precedence is at least minPrecedence.
+func (p *infixParser) parse(minPrecedence int) (*ExprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != exprOperatorToken {
+			return left, nil
+		}
+		op, binary := exprOperators[t.text]
+		if !binary {
+			return nil, &ExpressionError{Pos: t.pos, Token: t.text, Reason: "not a binary operator"}
+		}
+		if op.precedence < minPrecedence {
+			return left, nil
+		}
+		p.next++
+		next := op.precedence + 1
+		if op.rightAssoc {
+			next = op.precedence
+		}
+		right, err := p.parse(next)
+		if err != nil {
+			return nil, err
+		}
+		left = newOperatorNode(t, left, right)
+	}
+}
+
+// parseUnary parses an operand, a parenthesized expression or a unary operator with its operand.
+func (p *infixParser) parseUnary() (*ExprNode, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, &ExpressionError{Pos: p.end, Reason: "missing operand"}
+	}
+	p.next++
+	switch t.kind {
+	case exprOperand:
+		return newOperandNode(t), nil
+	case exprLeftParen:
+		node, err := p.parse(1)
+		if err != nil {
+			return nil, err
+		}
+		closing, ok := p.peek()
+		if !ok || closing.kind != exprRightParen {
+			return nil, &ExpressionError{Pos: t.pos, Token: t.text, Reason: "unclosed parenthesis"}
+		}
+		p.next++
+		return node, nil
+	case exprOperatorToken:
+		if t.text == "-" || t.text == "+" || t.text == "!" {
+			operand, err := p.parse(unaryPrecedence)
+			if err != nil {
+				return nil, err
+			}
+			if t.text == "+" {
+				return operand, nil
+			}
+			return newOperatorNode(t, nil, operand), nil
+		}
+	}
+	return nil, &ExpressionError{Pos: t.pos, Token: t.text, Reason: "missing operand"}
+}
+
+// ParsePrefix parses an expression in prefix notation, such as "* a + b 2", into an expression tree.
+//
+// Tokens are separated by spaces where needed, the operators are the binary operators of ParseInfix.
+// An invalid expression gives an error matching ErrInvalidExpression.
+func ParsePrefix(expr string) (*ExprNode, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	// operators waiting for their operands, with the number of operands they have
+	type pending struct {
+		node     *ExprNode
+		operands int
+	}
+	var stack []pending
+	for i, t := range tokens {
+		var node *ExprNode
+		switch {
+		case t.kind == exprOperand:
+			node = newOperandNode(t)
+		case t.kind == exprOperatorToken && isBinaryOperator(t.text):
+			stack = append(stack, pending{node: newOperatorNode(t, nil, nil)})
+			continue
+		default:
+			return nil, &ExpressionError{Pos: t.pos, Token: t.text, Reason: "unexpected token"}
+		}
+		// the operand completes the operators whose second operand it is
+		for {
+			if len(stack) == 0 {
+				if i != len(tokens)-1 {
+					next := tokens[i+1]
+					return nil, &ExpressionError{Pos: next.pos, Token: next.text, Reason: "unexpected token"}
+				}
+				return node, nil
+			}
+			top := &stack[len(stack)-1]
+			if top.operands == 0 {
+				top.node.Left = node
+				top.operands = 1
+				break
+			}
+			top.node.Right = node
+			node = top.node
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return nil, &ExpressionError{Pos: len(expr), Reason: "missing operand"}
+}
+
+// ParsePostfix parses an expression in postfix notation, such as "a b 2 + *", into an expression tree.
+//
+// Tokens are separated by spaces where needed, the operators are the binary operators of ParseInfix.
+// An invalid expression gives an error matching ErrInvalidExpression.
+func ParsePostfix(expr string) (*ExprNode, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	var stack []*ExprNode
+	for _, t := range tokens {
+		switch {
+		case t.kind == exprOperand:
+			stack = append(stack, newOperandNode(t))
+		case t.kind == exprOperatorToken && isBinaryOperator(t.text):
+			if len(stack) < 2 {
+				return nil, &ExpressionError{Pos: t.pos, Token: t.text, Reason: "missing operand"}
+			}
+			left, right := stack[len(stack)-2], stack[len(stack)-1]
+			stack = append(stack[:len(stack)-2], newOperatorNode(t, left, right))
+		default:
+			return nil, &ExpressionError{Pos: t.pos, Token: t.text, Reason: "unexpected token"}
+		}
+	}
+	if len(stack) != 1 {
+		extra := stack[1]
+		return nil, &ExpressionError{Pos: extra.pos, Token: extra.Token, Reason: "missing operator"}
+	}
+	return stack[0], nil
+}
+
+func isBinaryOperator(token string) bool {
+	_, ok := exprOperators[token]
+	return ok
+}
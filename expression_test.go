@@ -0,0 +1,119 @@
+package bitreevis_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+func TestParseInfix(t *testing.T) {
+	cases := []struct {
+		expr, tree string
+	}{
+		{"1 + 2 * 3", "(1 + (2 * 3))"},
+		{"(1 + 2) * 3", "((1 + 2) * 3)"},
+		{"a - b - c", "((a - b) - c)"},
+		{"2 ^ 3 ^ 2", "(2 ^ (3 ^ 2))"},
+		{"-2^2 * -x", "((-(2 ^ 2)) * (-x))"},
+		{"+a", "a"},
+		{"t.price * 1.2 >= 100 && !t.deleted", "(((t.price * 1.2) >= 100) && (!t.deleted))"},
+		{"a<=b||c!=d", "((a <= b) || (c != d))"},
+	}
+	for _, c := range cases {
+		root, err := bitreevis.ParseInfix(c.expr)
+		require.NoError(t, err, c.expr)
+		require.Equal(t, c.tree, root.String(), c.expr)
+	}
+
+	for _, expr := range []string{"", "1 +", "(1 + 2", "1 + 2)", "1 2", "* 1", "1 $ 2", "1.2.3", "a ! b"} {
+		_, err := bitreevis.ParseInfix(expr)
+		require.True(t, errors.Is(err, bitreevis.ErrInvalidExpression), expr)
+	}
+	_, err := bitreevis.ParseInfix("(a + b")
+	var exprErr *bitreevis.ExpressionError
+	require.True(t, errors.As(err, &exprErr))
+	require.Equal(t, 0, exprErr.Pos)
+	require.Equal(t, "(", exprErr.Token)
+}
+
+func TestParsePrefixAndPostfix(t *testing.T) {
+	infix, err := bitreevis.ParseInfix("a * (b + 2) - c / 4")
+	require.NoError(t, err)
+	prefix, err := bitreevis.ParsePrefix("- * a + b 2 / c 4")
+	require.NoError(t, err)
+	postfix, err := bitreevis.ParsePostfix("a b 2 + * c 4 / -")
+	require.NoError(t, err)
+	require.Equal(t, infix.String(), prefix.String())
+	require.Equal(t, infix.String(), postfix.String())
+
+	for _, expr := range []string{"", "+ 1", "+ 1 2 3", "1", "( + 1 2 )", "! 1"} {
+		root, err := bitreevis.ParsePrefix(expr)
+		if expr == "1" {
+			require.NoError(t, err)
+			require.Equal(t, "1", root.String())
+			continue
+		}
+		require.True(t, errors.Is(err, bitreevis.ErrInvalidExpression), expr)
+	}
+	for _, expr := range []string{"", "1 +", "1 2", "1 2 + 3", "1 !"} {
+		_, err := bitreevis.ParsePostfix(expr)
+		require.True(t, errors.Is(err, bitreevis.ErrInvalidExpression), expr)
+	}
+}
+
+func TestExprNode_Evaluate(t *testing.T) {
+	root, err := bitreevis.ParseInfix("(x + 2) * 3 - 2 ^ 3 % 5")
+	require.NoError(t, err)
+	v, err := root.Evaluate(map[string]float64{"x": 4})
+	require.NoError(t, err)
+	require.Equal(t, 15.0, v)
+	require.Equal(t, 18.0, root.Left.Value)
+
+	root, err = bitreevis.ParseInfix("x > 1 && !(y == 2) || 0")
+	require.NoError(t, err)
+	v, err = root.Evaluate(map[string]float64{"x": 2, "y": 3})
+	require.NoError(t, err)
+	require.Equal(t, 1.0, v)
+
+	root, _ = bitreevis.ParseInfix("1 / (x - x)")
+	_, err = root.Evaluate(map[string]float64{"x": 1})
+	require.True(t, errors.Is(err, bitreevis.ErrInvalidExpression))
+	_, err = root.Evaluate(nil)
+	require.EqualError(t, err, `bitreevis: invalid expression at 5 "x": unknown variable`)
+
+	// names which strconv takes for numbers are variables
+	root, err = bitreevis.ParseInfix("inf + nan * Infinity")
+	require.NoError(t, err)
+	v, err = root.Evaluate(map[string]float64{"inf": 1, "nan": 2, "Infinity": 3})
+	require.NoError(t, err)
+	require.Equal(t, 7.0, v)
+	require.NoError(t, root.Annotate(map[string]float64{"inf": 1, "nan": 2, "Infinity": 3}))
+	require.True(t, root.Left.Annotated)
+	_, err = root.Evaluate(nil)
+	require.EqualError(t, err, `bitreevis: invalid expression at 0 "inf": unknown variable`)
+}
+
+func TestSvgRenderer_ExpressionTree(t *testing.T) {
+	root, err := bitreevis.ParseInfix("(x + 2) * 3")
+	require.NoError(t, err)
+	require.NoError(t, root.Annotate(map[string]float64{"x": 4}))
+	require.Equal(t, "*\n= 18", root.GetField())
+	require.Equal(t, "x\n= 4", root.Left.Left.GetField())
+	require.Equal(t, "3", root.Right.GetField())
+
+	content := renderForTest(t, root, nil)
+	requireWellFormed(t, content)
+	require.Equal(t, 2, strings.Count(content, bitreevis.DefaultOperatorColor))
+	require.Equal(t, 3, strings.Count(content, bitreevis.DefaultOperandColor))
+	require.Contains(t, content, ">= 18</tspan>")
+
+	content = renderForTest(t, root, &bitreevis.RenderOption{CSSClasses: true})
+	requireWellFormed(t, content)
+	// the classes are on the circles and the labels of nodes
+	require.Equal(t, 4, strings.Count(content, ` operator"`))
+	require.Equal(t, 6, strings.Count(content, ` operand"`))
+}
@@ -0,0 +1,293 @@
+package bitreevis
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Font describes the font used to render text.
+type Font struct {
+	// Family is a list of font families as in css, for example "Helvetica, Arial, sans-serif".
+	Family string
+	// Size is the font size in pixel.
+	Size float64
+	// Weight is the font weight as in css, for example "bold" or "600".
+	Weight string
+	// Style is the font style as in css, for example "italic".
+	Style string
+}
+
+// FontMetrics contains the vertical metrics of a font in pixel.
+type FontMetrics struct {
+	// Ascent is the distance from the baseline to the top of the highest glyphs.
+	Ascent float64
+	// Descent is the distance from the baseline to the bottom of the lowest glyphs, it is positive.
+	Descent float64
+	// CapHeight is the height of capital letters and digits above the baseline.
+	CapHeight float64
+}
+
+// A TextMeasurer measures text rendered with a font.
+//
+// It is shared by the layout, for sizing nodes, and by renderers, for centring and truncating text.
+// DefaultTextMeasurer is used if RenderOption.TextMeasurer is nil. A TextMeasurer backed by real font
+// files, for example with golang.org/x/image/font, can be plugged in for exact results.
+type TextMeasurer interface {
+	// MeasureString returns the advance width of text rendered with font.
+	MeasureString(text string, font Font) float64
+	// Metrics returns the vertical metrics of font.
+	Metrics(font Font) FontMetrics
+}
+
+// DefaultTextMeasurer measures text with built-in approximate metrics of the standard Helvetica,
+// Times and Courier fonts, chosen by the generic family of Font.Family.
+var DefaultTextMeasurer TextMeasurer = approximateTextMeasurer{}
+
+// fontTable holds the metrics of a font in units of 1/1000 em.
+type fontTable struct {
+	// widths are the advance widths of the printable ascii characters from ' ' to '~'.
+	widths    [95]uint16
+	ascent    float64
+	descent   float64
+	capHeight float64
+	// otherWidth is the width of the characters which are not in widths.
+	otherWidth float64
+}
+
+var (
+	// helveticaTable is used for sans-serif families.
+	helveticaTable = &fontTable{
+		widths: [95]uint16{
+			278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // ' ' - '/'
+			556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // '0' - '9'
+			278, 278, 584, 584, 584, 556, 1015, // ':' - '@'
+			667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, // 'A' - 'M'
+			722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // 'N' - 'Z'
+			278, 278, 278, 469, 556, 333, // '[' - '`'
+			556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, // 'a' - 'm'
+			556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, // 'n' - 'z'
+			334, 260, 334, 584, // '{' - '~'
+		},
+		ascent:     718,
+		descent:    207,
+		capHeight:  718,
+		otherWidth: 600,
+	}
+
+	// timesTable is used for serif families.
+	timesTable = &fontTable{
+		widths: [95]uint16{
+			250, 333, 408, 500, 500, 833, 778, 180, 333, 333, 500, 564, 250, 333, 250, 278, // ' ' - '/'
+			500, 500, 500, 500, 500, 500, 500, 500, 500, 500, // '0' - '9'
+			278, 278, 564, 564, 564, 444, 921, // ':' - '@'
+			722, 667, 667, 722, 611, 556, 722, 722, 333, 389, 722, 611, 889, // 'A' - 'M'
+			722, 722, 556, 722, 667, 556, 611, 722, 722, 944, 722, 722, 611, // 'N' - 'Z'
+			333, 278, 333, 469, 500, 333, // '[' - '`'
+			444, 500, 444, 500, 444, 333, 500, 500, 278, 278, 500, 278, 778, // 'a' - 'm'
+			500, 500, 500, 500, 333, 389, 278, 500, 500, 722, 500, 500, 444, // 'n' - 'z'
+			480, 200, 480, 541, // '{' - '~'
+		},
+		ascent:     683,
+		descent:    217,
+		capHeight:  662,
+		otherWidth: 550,
+	}
+
+	// courierTable is used for monospace families.
+	courierTable = func() *fontTable {
+		t := &fontTable{ascent: 629, descent: 157, capHeight: 562, otherWidth: 600}
+		for i := range t.widths {
+			t.widths[i] = 600
+		}
+		return t
+	}()
+)
+
+const (
+	// boldWidthFactor approximates how much wider bold glyphs are than regular ones.
+	boldWidthFactor = 1.06
+	// wideWidth is the width of east asian wide characters in units of 1/1000 em.
+	wideWidth = 1000
+)
+
+// approximateTextMeasurer implements TextMeasurer with built-in font tables.
+type approximateTextMeasurer struct{}
+
+func (approximateTextMeasurer) MeasureString(text string, font Font) float64 {
+	table := lookupFontTable(font.Family)
+	var units float64
+	for _, r := range text {
+		switch {
+		case r >= ' ' && r <= '~':
+			units += float64(table.widths[r-' '])
+		case isWideRune(r):
+			units += wideWidth
+		default:
+			units += table.otherWidth
+		}
+	}
+	width := units * font.Size / 1000
+	if isBold(font.Weight) {
+		width *= boldWidthFactor
+	}
+	return width
+}
+
+func (approximateTextMeasurer) Metrics(font Font) FontMetrics {
+	table := lookupFontTable(font.Family)
+	return FontMetrics{
+		Ascent:    table.ascent * font.Size / 1000,
+		Descent:   table.descent * font.Size / 1000,
+		CapHeight: table.capHeight * font.Size / 1000,
+	}
+}
+
+// maxCachedFontFamilies is the maximum number of font family strings whose tables are cached.
+const maxCachedFontFamilies = 64
+
+var (
+	// fontTableCache is a map[string]*fontTable from font family strings to their tables. It is replaced
+	// instead of modified, so that lookups need neither a lock nor an allocation.
+	fontTableCache   atomic.Value
+	fontTableCacheMu sync.Mutex
+)
+
+// lookupFontTable returns the table of the first family in family which can be recognized.
+// Helvetica is used if no family can be recognized.
+//
+// It is called for every piece of text measured, so the tables are cached by family.
+func lookupFontTable(family string) *fontTable {
+	cache, _ := fontTableCache.Load().(map[string]*fontTable)
+	if table, ok := cache[family]; ok {
+		return table
+	}
+	table := parseFontTable(family)
+
+	fontTableCacheMu.Lock()
+	defer fontTableCacheMu.Unlock()
+	cache, _ = fontTableCache.Load().(map[string]*fontTable)
+	if len(cache) < maxCachedFontFamilies {
+		next := make(map[string]*fontTable, len(cache)+1)
+		for k, v := range cache {
+			next[k] = v
+		}
+		next[family] = table
+		fontTableCache.Store(next)
+	}
+	return table
+}
+
+// parseFontTable returns the table of the first family in family which can be recognized.
+// Helvetica is used if no family can be recognized.
+func parseFontTable(family string) *fontTable {
+	for _, f := range strings.Split(family, ",") {
+		f = strings.ToLower(strings.Trim(strings.TrimSpace(f), `"'`))
+		switch {
+		case f == "":
+			continue
+		case f == "monospace" || strings.Contains(f, "mono") || strings.Contains(f, "courier") ||
+			strings.Contains(f, "consolas") || strings.Contains(f, "menlo"):
+			return courierTable
+		case f == "sans-serif" || strings.Contains(f, "sans") || strings.Contains(f, "helvetica") ||
+			strings.Contains(f, "arial"):
+			return helveticaTable
+		case f == "serif" || strings.Contains(f, "times") || strings.Contains(f, "georgia"):
+			return timesTable
+		}
+	}
+	return helveticaTable
+}
+
+// isWideRune reports whether r is usually rendered as a full-width character.
+func isWideRune(r rune) bool {
+	return r >= 0x1100 && (unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana) ||
+		(r >= 0xFF00 && r <= 0xFF60) || (r >= 0x1F300 && r <= 0x1FAFF))
+}
+
+// isBold reports whether css font weight is bold.
+func isBold(weight string) bool {
+	switch weight {
+	case "bold", "bolder", "600", "700", "800", "900":
+		return true
+	}
+	return false
+}
+
+// nodeFieldFont returns the font of text inside of node.
+func (opt *RenderOption) nodeFieldFont() Font {
+	size := opt.NodeFieldTextSize
+	if size == 0 {
+		size = DefaultNodeFieldTextSize
+	}
+	return Font{
+		Family: opt.NodeFieldFontFamily,
+		Size:   float64(size),
+		Weight: opt.NodeFieldFontWeight,
+		Style:  opt.NodeFieldFontStyle,
+	}
+}
+
+// textMeasurer returns the TextMeasurer of the option.
+func (opt *RenderOption) textMeasurer() TextMeasurer {
+	if opt.TextMeasurer != nil {
+		return opt.TextMeasurer
+	}
+	return DefaultTextMeasurer
+}
+
+// labelBlock is the lines of a label laid out with a font.
+type labelBlock struct {
+	lines     []string
+	truncated bool
+	// width is the width of the widest line.
+	width float64
+	// height is the height from the cap height of the first line to the baseline of the last line.
+	height float64
+	// firstDy is the vertical offset of the baseline of the first line, relative to the centre of the block.
+	firstDy float64
+	// lineHeight is the distance between the baselines of two adjacent lines.
+	lineHeight float64
+}
+
+// layoutNodeField lays out text inside of node according to opt, the lines are appended to dst.
+func layoutNodeField(dst []string, text string, opt *RenderOption) labelBlock {
+	font := opt.nodeFieldFont()
+	measurer := opt.textMeasurer()
+	measure := func(s string) float64 { return measurer.MeasureString(s, font) }
+
+	block := labelBlock{lineHeight: font.Size * defaultLineHeight}
+	block.lines, block.truncated = layoutLabel(dst, text, float64(opt.NodeFieldMaxWidth), opt.NodeFieldOverflow, opt.NodeFieldMaxLines, measure)
+	for _, line := range block.lines {
+		block.width = math.Max(block.width, measure(line))
+	}
+	capHeight := measurer.Metrics(font).CapHeight
+	block.height = capHeight + float64(len(block.lines)-1)*block.lineHeight
+	// center the lines vertically
+	block.firstDy = capHeight - block.height/2
+
+	return block
+}
+
+// FitNodeRadius returns the smallest radius which is not less than opt.NodeRadius and makes the text
+// of every node in the tree rooted at root fit inside of the node, measured with the font of opt.
+func FitNodeRadius(root *PlaceableNode, opt *RenderOption) int {
+	radius := float64(opt.NodeRadius)
+	var linesBuf [4]string
+	for _, node := range root.CollectNodes() {
+		if node.Collapsed || utf8.RuneCountInString(node.Field) == 0 {
+			continue
+		}
+		block := layoutNodeField(linesBuf[:0], node.Field, opt)
+		// the text block is inscribed in the circle
+		needed := math.Hypot(block.width/2, block.height/2) + nodeTextPadding
+		radius = math.Max(radius, needed)
+	}
+	return int(math.Ceil(radius))
+}
+
+// nodeTextPadding is the minimum gap between text and the border of node when fitting.
+const nodeTextPadding = 2
@@ -0,0 +1,74 @@
+package bitreevis_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+func TestDefaultTextMeasurer(t *testing.T) {
+	m := bitreevis.DefaultTextMeasurer
+
+	require.InDelta(t, 5.56*3, m.MeasureString("123", bitreevis.Font{Size: 10}), 1e-9)
+	require.InDelta(t, 6.0*3, m.MeasureString("abc", bitreevis.Font{Family: "Menlo, monospace", Size: 10}), 1e-9)
+	require.InDelta(t, 5.0*3, m.MeasureString("123", bitreevis.Font{Family: `"Times New Roman", serif`, Size: 10}), 1e-9)
+	require.Greater(t,
+		m.MeasureString("abc", bitreevis.Font{Size: 10, Weight: "bold"}),
+		m.MeasureString("abc", bitreevis.Font{Size: 10}))
+	require.InDelta(t, 20.0, m.MeasureString("树木", bitreevis.Font{Size: 10}), 1e-9)
+
+	metrics := m.Metrics(bitreevis.Font{Size: 10})
+	require.InDelta(t, 7.18, metrics.CapHeight, 1e-9)
+	require.InDelta(t, 2.07, metrics.Descent, 1e-9)
+
+	// text is measured several times for each node, so measuring must not allocate
+	font := bitreevis.Font{Family: `"Helvetica Neue", Arial, sans-serif`, Size: 10}
+	require.Zero(t, testing.AllocsPerRun(100, func() {
+		m.MeasureString("abc", font)
+		m.Metrics(font)
+	}))
+}
+
+type fixedTextMeasurer struct{}
+
+func (fixedTextMeasurer) MeasureString(text string, font bitreevis.Font) float64 {
+	return float64(len(text)) * font.Size
+}
+
+func (fixedTextMeasurer) Metrics(font bitreevis.Font) bitreevis.FontMetrics {
+	return bitreevis.FontMetrics{Ascent: font.Size, CapHeight: font.Size}
+}
+
+func TestFitNodeRadius(t *testing.T) {
+	root := &labelNode{Label: "ab", Left: &labelNode{Label: "abcdefgh"}}
+	pRoot := bitreevis.NewPlaceableTreeFromBiNode(root)
+
+	opt := &bitreevis.RenderOption{NodeRadius: 10, NodeFieldTextSize: 6, TextMeasurer: fixedTextMeasurer{}}
+	// the widest label is 48 wide and 6 high
+	require.Equal(t, 27, bitreevis.FitNodeRadius(pRoot, opt))
+
+	opt.NodeRadius = 40
+	require.Equal(t, 40, bitreevis.FitNodeRadius(pRoot, opt))
+}
+
+func TestSvgRenderer_Font(t *testing.T) {
+	root := &labelNode{Label: "a long label", Left: &labelNode{Label: "b"}}
+	content := renderForTest(t, root, &bitreevis.RenderOption{
+		NodeRadius:          10,
+		NodeFieldFontFamily: "Courier New, monospace",
+		NodeFieldFontWeight: "bold",
+		NodeFieldFontStyle:  "italic",
+	})
+	require.Contains(t, content, "font-family:Courier New, monospace;font-weight:bold;font-style:italic")
+
+	filename := filepath.Join(t.TempDir(), "fit.svg")
+	require.Nil(t, bitreevis.VisAsSvg(root, filename, &bitreevis.RenderOption{NodeRadius: 10, NodeFitText: true}))
+	fitted, err := os.ReadFile(filename)
+	require.Nil(t, err)
+	require.NotContains(t, string(fitted), `r="10.000"`)
+	require.Contains(t, string(fitted), `r="44.000"`)
+}
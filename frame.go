@@ -0,0 +1,235 @@
+package bitreevis
+
+import (
+	"math"
+	"strconv"
+)
+
+// Sizes of the title, the subtitle, the caption and the legend entries around the tree.
+const (
+	titleTextSize      = 20
+	subtitleTextSize   = 14
+	captionTextSize    = 12
+	legendSwatchRadius = 6
+	legendRowHeight    = 20
+	// legendEntryGap is the horizontal gap between two legend entries.
+	legendEntryGap = 16
+)
+
+// legendEntryKind is the kind of the swatch of a legend entry.
+type legendEntryKind int
+
+const (
+	legendEntryColor legendEntryKind = iota
+	legendEntryHighlight
+	legendEntryPlaceholder
+)
+
+// legendEntry is an entry of the legend explaining a style of nodes.
+type legendEntry struct {
+	kind  legendEntryKind
+	color string
+	label string
+	// x and y are the centre of the swatch on the canvas.
+	x, y float64
+}
+
+// collectLegendEntries returns the legend entries explaining the styles used by nodes: the local colors
+// of PaintableBiNode in order of appearance, highlighted nodes and placeholders.
+func collectLegendEntries(nodes []*PlaceableNode, opt *RenderOption) []legendEntry {
+	var entries []legendEntry
+	seen := make(map[string]bool)
+	highlighted, collapsed := false, false
+	for _, node := range nodes {
+		highlighted = highlighted || (opt.Highlight != nil && node.Highlighted)
+		collapsed = collapsed || node.Collapsed
+		if node.Color == "" || seen[node.Color] {
+			continue
+		}
+		seen[node.Color] = true
+		label := node.Color
+		if l, ok := opt.LegendLabels[node.Color]; ok {
+			label = l
+		}
+		// node has a local color, so the colors of the palette are not needed
+		entries = append(entries, legendEntry{kind: legendEntryColor, color: nodeOwnColor(node, opt, nil), label: label})
+	}
+	if highlighted {
+		entries = append(entries, legendEntry{kind: legendEntryHighlight, color: opt.NodeColor, label: "highlighted"})
+	}
+	if collapsed {
+		entries = append(entries, legendEntry{kind: legendEntryPlaceholder, color: opt.PlaceholderColor, label: "collapsed subtree"})
+	}
+	return entries
+}
+
+// frameText is a block of text around the tree.
+type frameText struct {
+	lines []string
+	class string
+	size  float64
+	bold  bool
+	// x is the centre of the lines, y is the baseline of the first line.
+	x, y float64
+}
+
+// canvasLayout is the arrangement of the canvas: the title and the subtitle above the tree,
+// the legend and the caption below the tree.
+type canvasLayout struct {
+	width, height float64
+	// treeLeft and treeTop are the left and the top of the area of the tree.
+	treeLeft, treeTop float64
+	texts             []frameText
+	entries           []legendEntry
+	// gradientTop is the top of the gradient legend, if there is one.
+	gradientTop float64
+}
+
+// arrangeCanvas arranges the canvas around a tree area of treeWidth and treeHeight, so that nothing overlaps.
+// marginLeft is reserved on the left of the tree area for level labels.
+// The canvas is widened to fit the title and the subtitle, the legend entries and the caption are wrapped
+// to the width of the canvas.
+func arrangeCanvas(treeWidth, treeHeight, marginLeft float64, entries []legendEntry, gradient *GradientLegend, opt *RenderOption) canvasLayout {
+	measurer := opt.textMeasurer()
+	fontOf := func(size float64, bold bool) Font {
+		f := Font{Family: opt.NodeFieldFontFamily, Size: size}
+		if bold {
+			f.Weight = "bold"
+		}
+		return f
+	}
+	hpad, vpad := float64(opt.horizontalPadding()), float64(opt.verticalPadding())
+
+	l := canvasLayout{width: marginLeft + treeWidth, treeLeft: marginLeft}
+	header := []frameText{
+		{lines: []string{opt.Title}, class: classTitle, size: titleTextSize, bold: true},
+		{lines: []string{opt.Subtitle}, class: classSubtitle, size: subtitleTextSize},
+	}
+	for _, t := range header {
+		if t.lines[0] != "" {
+			l.width = math.Max(l.width, math.Ceil(measurer.MeasureString(t.lines[0], fontOf(t.size, t.bold))+2*hpad))
+		}
+	}
+	entryFont := fontOf(legendTextSize, false)
+	entryWidths := make([]float64, len(entries))
+	for i, e := range entries {
+		entryWidths[i] = 2*legendSwatchRadius + legendGap + measurer.MeasureString(e.label, entryFont)
+		l.width = math.Max(l.width, math.Ceil(entryWidths[i]+2*hpad))
+	}
+
+	// header
+	y := 0.0
+	for _, t := range header {
+		if t.lines[0] == "" {
+			continue
+		}
+		if y == 0 {
+			y = vpad
+		}
+		t.x, t.y = l.width/2, y+t.size
+		l.texts = append(l.texts, t)
+		y += t.size * defaultLineHeight
+	}
+
+	// tree
+	l.treeTop = y
+	y += treeHeight
+
+	// footer
+	footer := false
+	if len(entries) != 0 {
+		footer = true
+		x, rowTop := hpad, y
+		for i := range entries {
+			if x > hpad && x+entryWidths[i] > l.width-hpad {
+				x, rowTop = hpad, rowTop+legendRowHeight
+			}
+			entries[i].x, entries[i].y = x+legendSwatchRadius, rowTop+legendRowHeight/2
+			x += entryWidths[i] + legendEntryGap
+		}
+		l.entries = entries
+		y = rowTop + legendRowHeight
+	}
+	if gradient != nil {
+		l.gradientTop = y
+		y += float64(legendHeight(gradient))
+	}
+	if opt.Caption != "" {
+		footer = true
+		font := fontOf(captionTextSize, false)
+		measure := func(s string) float64 { return measurer.MeasureString(s, font) }
+		lines, _ := layoutLabel(nil, opt.Caption, l.width-2*hpad, TextOverflowWrap, 0, measure)
+		l.texts = append(l.texts, frameText{lines: lines, class: classCaption, size: captionTextSize, x: l.width / 2, y: y + captionTextSize})
+		y += float64(len(lines)) * captionTextSize * defaultLineHeight
+	}
+	if footer {
+		y += vpad
+	}
+	l.height = y
+
+	return l
+}
+
+// addFrame renders the texts and the legend around the tree.
+func (sr *SvgRenderer) addFrame(opt *RenderOption) {
+	for _, t := range sr.layout.texts {
+		sr.addFrameText(t.x, t.y, t.lines, t.size, t.bold, "middle", t.class, opt)
+	}
+	for _, e := range sr.layout.entries {
+		sr.addLegendEntry(e, opt)
+	}
+	if sr.legend != nil {
+		sr.addLegend(float32(sr.layout.width), float32(sr.layout.gradientTop), opt)
+	}
+}
+
+// addLegendEntry renders the swatch and the label of e.
+func (sr *SvgRenderer) addLegendEntry(e legendEntry, opt *RenderOption) {
+	r := float32(legendSwatchRadius)
+	x, y := float32(e.x), float32(e.y)
+	var attrs []svgAttribute
+	switch {
+	case e.kind == legendEntryPlaceholder && opt.CSSClasses:
+		attrs = []svgAttribute{{key: "class", value: classPlaceholder}}
+	case e.kind == legendEntryHighlight && opt.CSSClasses:
+		attrs = []svgAttribute{{key: "class", value: classNode + " " + classHighlighted}}
+	case e.kind == legendEntryHighlight:
+		attrs = []svgAttribute{styleAttr([]svgStyleAttribute{
+			{key: "fill", value: e.color},
+			{key: "stroke", value: opt.highlightColor()},
+			{key: "stroke-width", value: "2"},
+		})}
+	default:
+		attrs = []svgAttribute{styleAttr([]svgStyleAttribute{{key: "fill", value: e.color}})}
+	}
+	if e.kind == legendEntryPlaceholder {
+		sr.constructPolygon([]float32{x, x - r, x + r}, []float32{y - r, y + r, y + r}, attrs)
+	} else {
+		sr.constructCircle(x, y, r, attrs)
+	}
+	sr.addLegendText(x+r+legendGap, y+legendTextSize/3, e.label, "start", opt)
+}
+
+// addFrameText renders lines of text of size around the tree, with the baseline of the first line at y.
+// The text has the color of edges, which is visible on the background.
+func (sr *SvgRenderer) addFrameText(x, y float64, lines []string, size float64, bold bool, anchor, class string, opt *RenderOption) {
+	if opt.CSSClasses {
+		sr.constructText(float32(x), float32(y), lines, 0, size*defaultLineHeight, "", []svgAttribute{
+			{key: "class", value: class},
+			{key: "text-anchor", value: anchor},
+		})
+		return
+	}
+	styles := []svgStyleAttribute{
+		{key: "text-anchor", value: anchor},
+		{key: "font-size", value: strconv.Itoa(int(size))},
+	}
+	if opt.NodeFieldFontFamily != "" {
+		styles = append(styles, svgStyleAttribute{key: "font-family", value: opt.NodeFieldFontFamily})
+	}
+	if bold {
+		styles = append(styles, svgStyleAttribute{key: "font-weight", value: "bold"})
+	}
+	styles = append(styles, svgStyleAttribute{key: "fill", value: opt.EdgeLineColor})
+	sr.constructText(float32(x), float32(y), lines, 0, size*defaultLineHeight, "", []svgAttribute{styleAttr(styles)})
+}
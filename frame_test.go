@@ -0,0 +1,68 @@
+package bitreevis_test
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+// svgSize returns the width and height of the svg document in content.
+func svgSize(t *testing.T, content string) (int, int) {
+	m := regexp.MustCompile(`<svg width="(\d+)" height="(\d+)"`).FindStringSubmatch(content)
+	require.NotNil(t, m)
+	w, _ := strconv.Atoi(m[1])
+	h, _ := strconv.Atoi(m[2])
+	return w, h
+}
+
+func newRbTreeForTest() *rbNode {
+	return &rbNode{Value: 2, Color: "black", Left: &rbNode{Value: 1, Color: "red"}, Right: &rbNode{Value: 3, Color: "red"}}
+}
+
+func TestSvgRenderer_TitleAndCaption(t *testing.T) {
+	plainW, plainH := svgSize(t, renderForTest(t, newRbTreeForTest(), nil))
+
+	content := renderForTest(t, newRbTreeForTest(), &bitreevis.RenderOption{
+		Title:    "A rather long title of the incident review diagram",
+		Subtitle: "after insertion",
+		Caption:  strings.Repeat("caption words ", 30),
+	})
+	requireWellFormed(t, content)
+	w, h := svgSize(t, content)
+	// the canvas is widened for the title and heightened for the texts
+	require.Greater(t, w, plainW)
+	require.Greater(t, h, plainH+20+14+3*12)
+	require.Contains(t, content, "A rather long title")
+	require.Contains(t, content, "font-weight:bold")
+	// the tree is moved down below the title and the subtitle
+	require.Contains(t, content, `transform="translate(`+strconv.Itoa(w/2)+`.000,80.800)"`)
+	// the caption is wrapped
+	require.Greater(t, strings.Count(content, `<tspan x="`+strconv.Itoa(w/2)+`.000" dy="14.400" >`), 1)
+}
+
+func TestSvgRenderer_Legend(t *testing.T) {
+	content := renderForTest(t, newRbTreeForTest(), &bitreevis.RenderOption{
+		Legend:       true,
+		LegendLabels: map[string]string{"red": "red node"},
+		NodeColorMap: map[string]string{"black": "#222222"},
+		Highlight:    bitreevis.HighlightSearch("3"),
+	})
+	requireWellFormed(t, content)
+	require.Contains(t, content, ">red node</tspan>")
+	require.Contains(t, content, ">black</tspan>")
+	require.Contains(t, content, ">highlighted</tspan>")
+	require.NotContains(t, content, ">collapsed subtree</tspan>")
+	require.Contains(t, content, `style="fill:#222222" cx="`)
+	_, plainH := svgSize(t, renderForTest(t, newRbTreeForTest(), nil))
+	_, h := svgSize(t, content)
+	// the tree is too narrow for two entries in a row
+	require.Equal(t, plainH+3*20+10, h)
+
+	content = renderForTest(t, newBstForTest(), &bitreevis.RenderOption{Legend: true, MaxNodes: 3})
+	require.Contains(t, content, ">collapsed subtree</tspan>")
+}
@@ -0,0 +1,298 @@
+package bitreevis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Layout is the geometry of a laid out tree: the positions and the sizes of nodes, the polylines of edges
+// and the bounding box of the drawing, in the coordinates of layout where the root is at (0, 0).
+//
+// A Layout is a plain value computed by ComputeLayout, which does not refer to the tree it was computed from
+// and is never modified by bitreevis. It can be serialised to JSON, cached, compared with Diff and rendered
+// by any Renderer through PlaceableTree.
+type Layout struct {
+	// Nodes are the nodes in in-order.
+	Nodes []LayoutNode `json:"nodes"`
+	// Edges are the edges from parents to children, in the order of their parents in Nodes, left edges first.
+	Edges []LayoutEdge `json:"edges"`
+	// Bounds is the bounding box of the drawing, including labels, arrows and strokes.
+	Bounds LayoutRect `json:"bounds"`
+}
+
+// LayoutNode is a node of Layout.
+type LayoutNode struct {
+	// ID is the key of the node, which is the key of IdentifiableBiNode if implemented, otherwise its traversal
+	// path from the root made of 'L' and 'R', as in element ids. The ID of the root is empty. IDs are unique,
+	// long paths and duplicate keys are shortened and suffixed as described in RenderOption.ElementIDs.
+	ID     string  `json:"id"`
+	Field  string  `json:"field"`
+	Color  string  `json:"color,omitempty"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Radius float64 `json:"radius"`
+	Depth  int     `json:"depth"`
+	// Parent, Left and Right are the indices of the parent and the children of the node in Layout.Nodes,
+	// -1 if there is none.
+	Parent int `json:"parent"`
+	Left   int `json:"left"`
+	Right  int `json:"right"`
+	// Collapsed reports whether the node is a placeholder of a truncated subtree of HiddenNodes nodes
+	// and HiddenHeight levels.
+	Collapsed    bool `json:"collapsed,omitempty"`
+	HiddenNodes  int  `json:"hiddenNodes,omitempty"`
+	HiddenHeight int  `json:"hiddenHeight,omitempty"`
+}
+
+// LayoutEdge is an edge of Layout.
+type LayoutEdge struct {
+	// From and To are the indices of the parent and the child in Layout.Nodes.
+	From int `json:"from"`
+	To   int `json:"to"`
+	// Left reports whether the child is the left child.
+	Left bool `json:"left"`
+	// Points is the polyline of the edge from the border of the parent to the border of the child,
+	// curves are approximated by line segments.
+	Points []LayoutPoint `json:"points"`
+}
+
+// LayoutPoint is a point in the coordinates of layout.
+type LayoutPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// LayoutRect is a rectangle in the coordinates of layout.
+type LayoutRect struct {
+	MinX float64 `json:"minX"`
+	MinY float64 `json:"minY"`
+	MaxX float64 `json:"maxX"`
+	MaxY float64 `json:"maxY"`
+}
+
+// curveSegments is the number of line segments approximating a curve in the polyline of an edge.
+const curveSegments = 8
+
+// ComputeLayout lays out the tree rooted at root with opt, as VisAsSvg does, and returns its geometry.
+// root is not modified. If opt is nil, the default option is used. If opt is invalid, an error matching
+// ErrInvalidOption is returned. If root is nil, an empty Layout is returned.
+func ComputeLayout(root BiNode, opt *RenderOption) (Layout, error) {
+	if err := opt.Validate(); err != nil {
+		return Layout{}, err
+	}
+	opt = opt.WithDefaults()
+
+	pRoot := NewPlaceableTreeFromBiNodeWithOption(root, opt)
+	if pRoot == nil {
+		return Layout{}, nil
+	}
+	if opt.NodeFitText {
+		opt.NodeRadius = FitNodeRadius(pRoot, opt)
+	}
+	pRoot = LayoutWithOption(pRoot, opt)
+	return newLayout(pRoot, opt), nil
+}
+
+// newLayout returns the geometry of the laid out tree rooted at root.
+func newLayout(root *PlaceableNode, opt *RenderOption) Layout {
+	nodes := root.CollectNodes()
+	keys := buildElementKeys(root)
+	index := make(map[*PlaceableNode]int, len(nodes))
+	for i, node := range nodes {
+		index[node] = i
+	}
+	// the parent of the visible root may be outside of the tree
+	indexOf := func(node *PlaceableNode) int {
+		if i, ok := index[node]; ok {
+			return i
+		}
+		return -1
+	}
+
+	l := Layout{Nodes: make([]LayoutNode, len(nodes))}
+	radius := float64(opt.NodeRadius)
+	offsetEnd := edgeOffsetEnd(opt)
+	for i, node := range nodes {
+		l.Nodes[i] = LayoutNode{
+			ID:           keys[node],
+			Field:        node.Field,
+			Color:        node.Color,
+			X:            float64(node.X),
+			Y:            float64(node.Y),
+			Radius:       radius,
+			Depth:        node.Depth,
+			Parent:       indexOf(node.Parent),
+			Left:         indexOf(node.Left),
+			Right:        indexOf(node.Right),
+			Collapsed:    node.Collapsed,
+			HiddenNodes:  node.HiddenNodes,
+			HiddenHeight: node.HiddenHeight,
+		}
+		for side, child := range []*PlaceableNode{node.Left, node.Right} {
+			if child == nil {
+				continue
+			}
+			route := routeEdge(opt.EdgeRouting, node, child, root, radius, offsetEnd)
+			l.Edges = append(l.Edges, LayoutEdge{
+				From:   i,
+				To:     index[child],
+				Left:   edgeSide(side) == edgeLeft,
+				Points: route.polyline(opt.EdgeRouting),
+			})
+		}
+	}
+
+	b := tightBounds(root, nodes, opt)
+	l.Bounds = LayoutRect{MinX: b.minX, MinY: b.minY, MaxX: b.maxX, MaxY: b.maxY}
+	return l
+}
+
+// polyline returns the points of route drawn with routing, curves are approximated by line segments.
+func (r edgeRoute) polyline(routing EdgeRouting) []LayoutPoint {
+	start, end := LayoutPoint{X: r.startX, Y: r.startY}, LayoutPoint{X: r.endX, Y: r.endY}
+	switch {
+	case routing == EdgeRoutingOrthogonal:
+		return []LayoutPoint{start, {X: r.startX, Y: r.midY}, {X: r.endX, Y: r.midY}, end}
+	case routing == EdgeRoutingBezier:
+		points := make([]LayoutPoint, 0, curveSegments+1)
+		for i := 0; i <= curveSegments; i++ {
+			t := float64(i) / curveSegments
+			// the control points are (startX, midY) and (endX, midY)
+			a, b, c, d := math.Pow(1-t, 3), 3*t*(1-t)*(1-t), 3*t*t*(1-t), math.Pow(t, 3)
+			points = append(points, LayoutPoint{
+				X: (a+b)*r.startX + (c+d)*r.endX,
+				Y: a*r.startY + (b+c)*r.midY + d*r.endY,
+			})
+		}
+		return points
+	case routing == EdgeRoutingArc && r.arcRadius != 0:
+		points := make([]LayoutPoint, 0, curveSegments+3)
+		points = append(points, start)
+		for i := 0; i <= curveSegments; i++ {
+			x, y := r.arcPoint(float64(i) / curveSegments)
+			points = append(points, LayoutPoint{X: x, Y: y})
+		}
+		return append(points, end)
+	}
+	return []LayoutPoint{start, end}
+}
+
+// LayoutDiff is the difference between two Layouts, nodes are matched by their IDs.
+type LayoutDiff struct {
+	// Added are the IDs of nodes only in the new Layout.
+	Added []string
+	// Removed are the IDs of nodes only in the old Layout.
+	Removed []string
+	// Moved are the IDs of nodes in both Layouts whose positions differ.
+	Moved []string
+}
+
+// layoutMoveTolerance is the distance under which a node is not considered moved.
+const layoutMoveTolerance = 1e-3
+
+// Diff returns the difference from l to newer. The IDs are in the order of the nodes in their Layouts.
+func (l Layout) Diff(newer Layout) LayoutDiff {
+	var diff LayoutDiff
+	old := make(map[string]LayoutNode, len(l.Nodes))
+	for _, node := range l.Nodes {
+		old[node.ID] = node
+	}
+	seen := make(map[string]bool, len(newer.Nodes))
+	for _, node := range newer.Nodes {
+		seen[node.ID] = true
+		prev, ok := old[node.ID]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, node.ID)
+		case math.Hypot(prev.X-node.X, prev.Y-node.Y) > layoutMoveTolerance:
+			diff.Moved = append(diff.Moved, node.ID)
+		}
+	}
+	for _, node := range l.Nodes {
+		if !seen[node.ID] {
+			diff.Removed = append(diff.Removed, node.ID)
+		}
+	}
+	return diff
+}
+
+// ErrInvalidLayout is matched by the errors returned from Layout.PlaceableTree for a Layout whose nodes do not
+// form a tree, for example a Layout read back from corrupted JSON. It can be checked with errors.Is.
+var ErrInvalidLayout = errors.New("bitreevis: invalid layout")
+
+// PlaceableTree returns a new tree of PlaceableNode placed as in l, which can be rendered by any Renderer
+// with the NodeRadius of the Layout. The nodes have no Source. It returns nil if l is empty.
+//
+// The indices of nodes are checked, so that l can come from an untrusted source: every index must be in range,
+// the children of a node must have it as their Parent, and every node must be reachable from the only root.
+// Otherwise an error matching ErrInvalidLayout is returned.
+func (l Layout) PlaceableTree() (*PlaceableNode, error) {
+	if len(l.Nodes) == 0 {
+		return nil, nil
+	}
+	root := -1
+	for i, n := range l.Nodes {
+		for _, j := range []int{n.Parent, n.Left, n.Right} {
+			if j < -1 || j >= len(l.Nodes) {
+				return nil, fmt.Errorf("%w: node %d refers to node %d out of range", ErrInvalidLayout, i, j)
+			}
+		}
+		for _, j := range []int{n.Left, n.Right} {
+			if j >= 0 && l.Nodes[j].Parent != i {
+				return nil, fmt.Errorf("%w: child %d of node %d has parent %d", ErrInvalidLayout, j, i, l.Nodes[j].Parent)
+			}
+		}
+		if n.Left >= 0 && n.Left == n.Right {
+			return nil, fmt.Errorf("%w: node %d has node %d as both children", ErrInvalidLayout, i, n.Left)
+		}
+		if n.Parent < 0 {
+			if root >= 0 {
+				return nil, fmt.Errorf("%w: nodes %d and %d are both roots", ErrInvalidLayout, root, i)
+			}
+			root = i
+		}
+	}
+	if root < 0 {
+		return nil, fmt.Errorf("%w: no root", ErrInvalidLayout)
+	}
+	// every node is the child of its parent, so the nodes which cannot be reached from the root form cycles
+	reached := 0
+	for stack := []int{root}; len(stack) > 0; {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		reached++
+		for _, j := range []int{l.Nodes[i].Left, l.Nodes[i].Right} {
+			if j >= 0 {
+				stack = append(stack, j)
+			}
+		}
+	}
+	if reached != len(l.Nodes) {
+		return nil, fmt.Errorf("%w: %d nodes are not reachable from the root", ErrInvalidLayout, len(l.Nodes)-reached)
+	}
+
+	nodes := make([]PlaceableNode, len(l.Nodes))
+	link := func(i int) *PlaceableNode {
+		if i < 0 {
+			return nil
+		}
+		return &nodes[i]
+	}
+	for i, n := range l.Nodes {
+		nodes[i] = PlaceableNode{
+			Parent:       link(n.Parent),
+			Left:         link(n.Left),
+			Right:        link(n.Right),
+			X:            float32(n.X),
+			Y:            float32(n.Y),
+			Field:        n.Field,
+			Color:        n.Color,
+			Depth:        n.Depth,
+			Collapsed:    n.Collapsed,
+			HiddenNodes:  n.HiddenNodes,
+			HiddenHeight: n.HiddenHeight,
+		}
+	}
+	return &nodes[root], nil
+}
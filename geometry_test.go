@@ -0,0 +1,154 @@
+package bitreevis_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+func TestComputeLayout(t *testing.T) {
+	l, err := bitreevis.ComputeLayout(newBstForTest(), nil)
+	require.Nil(t, err)
+	require.Len(t, l.Nodes, 8)
+	require.Len(t, l.Edges, 7)
+
+	// nodes are in in-order, the root 5 is the fourth
+	root := l.Nodes[3]
+	require.Equal(t, "5", root.Field)
+	require.Equal(t, "", root.ID)
+	require.Equal(t, -1, root.Parent)
+	require.Equal(t, 0.0, root.X)
+	require.Equal(t, 0.0, root.Y)
+	require.Equal(t, "LR", l.Nodes[2].ID)
+	require.Equal(t, 1, l.Nodes[2].Parent)
+	require.Equal(t, -1, l.Nodes[2].Left)
+
+	for _, e := range l.Edges {
+		from, to := l.Nodes[e.From], l.Nodes[e.To]
+		require.Equal(t, e.Left, from.Left == e.To)
+		// edges go from border to border
+		first, last := e.Points[0], e.Points[len(e.Points)-1]
+		require.InDelta(t, from.Radius, math.Hypot(first.X-from.X, first.Y-from.Y), 1e-3)
+		require.InDelta(t, to.Radius, math.Hypot(last.X-to.X, last.Y-to.Y), 1e-3)
+	}
+	require.LessOrEqual(t, l.Bounds.MinX, l.Nodes[0].X-l.Nodes[0].Radius)
+	require.GreaterOrEqual(t, l.Bounds.MaxY, l.Nodes[7].Y+l.Nodes[7].Radius)
+
+	// the layout survives a round trip through JSON
+	data, err := json.Marshal(l)
+	require.Nil(t, err)
+	var decoded bitreevis.Layout
+	require.Nil(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, l, decoded)
+
+	l, err = bitreevis.ComputeLayout(nil, nil)
+	require.Nil(t, err)
+	require.Empty(t, l.Nodes)
+	pRoot, err := l.PlaceableTree()
+	require.Nil(t, err)
+	require.Nil(t, pRoot)
+
+	_, err = bitreevis.ComputeLayout(newBstForTest(), &bitreevis.RenderOption{NodeRadius: -1})
+	require.True(t, errors.Is(err, bitreevis.ErrInvalidOption))
+}
+
+func TestComputeLayout_EdgePolylines(t *testing.T) {
+	for routing, n := range map[bitreevis.EdgeRouting]int{
+		bitreevis.EdgeRoutingStraight:   2,
+		bitreevis.EdgeRoutingOrthogonal: 4,
+		bitreevis.EdgeRoutingBezier:     9,
+	} {
+		l, err := bitreevis.ComputeLayout(newBstForTest(), &bitreevis.RenderOption{EdgeRouting: routing})
+		require.Nil(t, err)
+		for _, e := range l.Edges {
+			require.Len(t, e.Points, n, routing)
+		}
+	}
+}
+
+func TestLayout_Diff(t *testing.T) {
+	opt := &bitreevis.RenderOption{Layout: bitreevis.LayoutInOrder}
+	before, err := bitreevis.ComputeLayout(newBstForTest(), opt)
+	require.Nil(t, err)
+	again, err := bitreevis.ComputeLayout(newBstForTest(), opt)
+	require.Nil(t, err)
+	require.Equal(t, bitreevis.LayoutDiff{}, before.Diff(again))
+
+	// 4 is inserted as the right child of 3 and 9 is removed,
+	// so the nodes before 4 are one more rank away from the root at x=0
+	root := newBstForTest()
+	root.Left.Right.Right = &myNode{Value: 4}
+	root.Right.Right.Right = nil
+	after, err := bitreevis.ComputeLayout(root, opt)
+	require.Nil(t, err)
+	require.Equal(t, bitreevis.LayoutDiff{
+		Added:   []string{"LRR"},
+		Removed: []string{"RRR"},
+		Moved:   []string{"LL", "L", "LR"},
+	}, before.Diff(after))
+}
+
+func TestLayout_PlaceableTree(t *testing.T) {
+	l, err := bitreevis.ComputeLayout(newBstForTest(), nil)
+	require.Nil(t, err)
+	pRoot, err := l.PlaceableTree()
+	require.Nil(t, err)
+	result := bitreevis.NewSvgRenderer().Render(pRoot, (&bitreevis.RenderOption{}).WithDefaults())
+	require.Nil(t, result.Error())
+	content, err := io.ReadAll(result.GetContent())
+	require.Nil(t, err)
+	// the reconstructed tree is rendered as the original one
+	require.Equal(t, renderForTest(t, newBstForTest(), nil), string(content))
+
+	// layouts which are not trees are rejected instead of panicking
+	corruptions := []func(nodes []bitreevis.LayoutNode){
+		func(nodes []bitreevis.LayoutNode) { nodes[0].Left = len(nodes) },
+		func(nodes []bitreevis.LayoutNode) { nodes[0].Parent = -2 },
+		func(nodes []bitreevis.LayoutNode) { nodes[1].Right = 0 },
+		func(nodes []bitreevis.LayoutNode) {
+			for i := range nodes {
+				nodes[i].Parent = -1
+			}
+		},
+		func(nodes []bitreevis.LayoutNode) {
+			for i := range nodes {
+				if nodes[i].Parent < 0 {
+					nodes[i].Parent = 0
+				}
+			}
+		},
+	}
+	for i, corrupt := range corruptions {
+		l, err := bitreevis.ComputeLayout(newBstForTest(), nil)
+		require.Nil(t, err)
+		corrupt(l.Nodes)
+		_, err = l.PlaceableTree()
+		require.True(t, errors.Is(err, bitreevis.ErrInvalidLayout), "corruption %d: %v", i, err)
+	}
+
+	// two nodes which are the children of each other are not reachable from the root
+	cycle := bitreevis.Layout{Nodes: []bitreevis.LayoutNode{
+		{Parent: -1, Left: -1, Right: -1},
+		{Parent: 2, Left: 2, Right: -1},
+		{Parent: 1, Left: 1, Right: -1},
+	}}
+	_, err = cycle.PlaceableTree()
+	require.True(t, errors.Is(err, bitreevis.ErrInvalidLayout))
+}
+
+func TestComputeLayout_DeepChain(t *testing.T) {
+	layout, err := bitreevis.ComputeLayout(newChainForTest(100_000, false), nil)
+	require.NoError(t, err)
+	require.Len(t, layout.Nodes, 100_000)
+	ids := make(map[string]bool, len(layout.Nodes))
+	for _, node := range layout.Nodes {
+		require.False(t, ids[node.ID], node.ID)
+		ids[node.ID] = true
+	}
+}
@@ -0,0 +1,84 @@
+package bitreevis
+
+import "fmt"
+
+// HeapNode is a node of a tree stored in a slice as a heap, it is made by FromHeapSlice or FromDaryHeapSlice.
+//
+// HeapNode implements IndexedBiNode, so the slice can be drawn below the tree with RenderOption.ArrayView.
+type HeapNode[T any] struct {
+	// Index is the index of the node in the slice.
+	Index int
+	// Value is the element of the slice at Index.
+	Value T
+
+	label       string
+	left, right *HeapNode[T]
+}
+
+func (n *HeapNode[T]) GetField() string {
+	return n.label
+}
+
+func (n *HeapNode[T]) GetLeftChild() BiNode {
+	if n.left == nil {
+		return nil
+	}
+	return n.left
+}
+
+func (n *HeapNode[T]) GetRightChild() BiNode {
+	if n.right == nil {
+		return nil
+	}
+	return n.right
+}
+
+func (n *HeapNode[T]) GetIndex() int {
+	return n.Index
+}
+
+// FromHeapSlice returns the root of the complete binary tree stored in s as a binary heap, in which the children
+// of the element at index i are at 2i+1 and 2i+2.
+//
+// label returns the text of an element, fmt.Sprint is used if label is nil. FromHeapSlice returns nil if s is empty.
+func FromHeapSlice[T any](s []T, label func(T) string) *HeapNode[T] {
+	return FromDaryHeapSlice(s, 2, label)
+}
+
+// FromDaryHeapSlice returns the root of the complete d-ary tree stored in s as a d-ary heap, in which the children
+// of the element at index i are at d*i+1 to d*i+d.
+//
+// A BiNode has two children at most, so if d is not 2, the tree is drawn in the left-child right-sibling
+// representation: the left child of a node is its first child, and its right child is its next sibling.
+// label returns the text of an element, fmt.Sprint is used if label is nil.
+// FromDaryHeapSlice returns nil if s is empty or d is less than 1.
+func FromDaryHeapSlice[T any](s []T, d int, label func(T) string) *HeapNode[T] {
+	if len(s) == 0 || d < 1 {
+		return nil
+	}
+	if label == nil {
+		label = func(v T) string { return fmt.Sprint(v) }
+	}
+	nodes := make([]HeapNode[T], len(s))
+	for i, v := range s {
+		nodes[i] = HeapNode[T]{Index: i, Value: v, label: label(v)}
+	}
+	child := func(i int) *HeapNode[T] {
+		if i < len(nodes) {
+			return &nodes[i]
+		}
+		return nil
+	}
+	for i := range nodes {
+		if d == 2 {
+			nodes[i].left, nodes[i].right = child(2*i+1), child(2*i+2)
+			continue
+		}
+		nodes[i].left = child(d*i + 1)
+		// the next sibling has the same parent, the root has no sibling
+		if i > 0 && i%d != 0 {
+			nodes[i].right = child(i + 1)
+		}
+	}
+	return &nodes[0]
+}
@@ -0,0 +1,73 @@
+package bitreevis_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+// heapShape returns the labels of the tree rooted at root in pre-order, with '.' for missing children.
+func heapShape(root bitreevis.BiNode) string {
+	if bitreevis.BiNodeIsNil(root) {
+		return "."
+	}
+	return root.GetField() + "(" + heapShape(root.GetLeftChild()) + " " + heapShape(root.GetRightChild()) + ")"
+}
+
+func TestFromHeapSlice(t *testing.T) {
+	heap := []int{1, 3, 2, 7, 4, 5}
+	root := bitreevis.FromHeapSlice(heap, nil)
+	require.Equal(t, "1(3(7(. .) 4(. .)) 2(5(. .) .))", heapShape(root))
+	right := root.GetRightChild().(*bitreevis.HeapNode[int])
+	require.Equal(t, 2, right.Index)
+	require.Equal(t, 2, right.Value)
+	require.Equal(t, 5, right.GetLeftChild().(bitreevis.IndexedBiNode).GetIndex())
+
+	root = bitreevis.FromHeapSlice(heap, func(v int) string { return "#" + strconv.Itoa(v) })
+	require.Equal(t, "#1", root.GetField())
+
+	require.Nil(t, bitreevis.FromHeapSlice([]int{}, nil))
+	require.Nil(t, bitreevis.FromDaryHeapSlice(heap, 0, nil))
+}
+
+func TestFromDaryHeapSlice(t *testing.T) {
+	// the left child is the first child, the right child is the next sibling
+	root := bitreevis.FromDaryHeapSlice([]string{"a", "b", "c", "d", "e", "f", "g"}, 3, nil)
+	require.Equal(t, "a(b(e(. f(. g(. .))) c(. d(. .))) .)", heapShape(root))
+
+	root = bitreevis.FromDaryHeapSlice([]string{"a", "b", "c"}, 1, nil)
+	require.Equal(t, "a(b(c(. .) .) .)", heapShape(root))
+}
+
+func TestSvgRenderer_ArrayView(t *testing.T) {
+	heap := []int{1, 3, 2, 7, 4, 5}
+	plain := renderForTest(t, bitreevis.FromHeapSlice(heap, nil), nil)
+	content := renderForTest(t, bitreevis.FromHeapSlice(heap, nil), &bitreevis.RenderOption{ArrayView: true})
+	requireWellFormed(t, content)
+	require.Equal(t, len(heap), strings.Count(content, "<rect")-strings.Count(plain, "<rect"))
+	require.Equal(t, len(heap), strings.Count(content, "stroke-dasharray:2 3"))
+	for i := range heap {
+		require.Contains(t, content, ">"+strconv.Itoa(i)+"</tspan>")
+	}
+	plainW, plainH := svgSize(t, plain)
+	w, h := svgSize(t, content)
+	require.GreaterOrEqual(t, w, plainW)
+	require.Greater(t, h, plainH)
+
+	content = renderForTest(t, bitreevis.FromHeapSlice(heap, nil), &bitreevis.RenderOption{
+		ArrayView:   true,
+		CSSClasses:  true,
+		TightBounds: true,
+	})
+	requireWellFormed(t, content)
+	require.Equal(t, len(heap), strings.Count(content, `class="array-cell"`))
+	require.Equal(t, len(heap), strings.Count(content, `class="array-index"`))
+	require.Equal(t, len(heap), strings.Count(content, `class="array-connector"`))
+
+	// trees of other nodes have no array
+	require.Equal(t, renderForTest(t, newBstForTest(), nil), renderForTest(t, newBstForTest(), &bitreevis.RenderOption{ArrayView: true}))
+}
@@ -0,0 +1,139 @@
+package bitreevis
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A Highlighter selects nodes of a binary tree which should be emphasised when rendering.
+//
+// Nodes can be selected by a predicate (Match), by their identities (Nodes) or by searching
+// a field value from the root as in a binary search tree (SearchField). All the selecting
+// methods can be combined. If WithPath is true, the path from the root to every selected node
+// is also emphasised. Nodes and edges which are not emphasised are dimmed by renderers.
+type Highlighter struct {
+	// Match reports whether the node should be highlighted. Match is ignored if nil.
+	Match func(node BiNode) bool
+	// Nodes lists the nodes which should be highlighted. Nodes are compared by identity,
+	// so the underlying type of each node should be comparable, usually a pointer.
+	// Nodes of types which cannot be compared are never highlighted by Nodes.
+	Nodes []BiNode
+	// SearchField specifies a field value which is searched from the root as in a binary search tree.
+	// SearchField is ignored if empty.
+	SearchField string
+	// SearchLess reports whether field a is less than field b during searching.
+	// If nil, fields are compared as numbers if both of them are numbers, otherwise as strings.
+	SearchLess func(a, b string) bool
+	// WithPath specifies whether to highlight the path from the root to each highlighted node.
+	// The path visited by SearchField is always highlighted.
+	WithPath bool
+}
+
+// HighlightNodes returns a Highlighter which highlights the given nodes and the paths from the root to them.
+func HighlightNodes(nodes ...BiNode) *Highlighter {
+	return &Highlighter{Nodes: nodes, WithPath: true}
+}
+
+// HighlightFunc returns a Highlighter which highlights the nodes matched by fn and the paths from the root to them.
+func HighlightFunc(fn func(node BiNode) bool) *Highlighter {
+	return &Highlighter{Match: fn, WithPath: true}
+}
+
+// HighlightSearch returns a Highlighter which highlights the path of searching field in a binary search tree.
+func HighlightSearch(field string) *Highlighter {
+	return &Highlighter{SearchField: field, WithPath: true}
+}
+
+// Apply marks the nodes of the tree rooted at root as highlighted according to h.
+// Marks set by a previous call are cleared first.
+//
+// Apply returns the number of distinct selected nodes, excluding the nodes on the paths.
+func (h *Highlighter) Apply(root *PlaceableNode) int {
+	nodes := root.CollectNodes()
+	for _, node := range nodes {
+		node.Highlighted = false
+		node.HighlightedEdge = false
+	}
+	if root == nil {
+		return 0
+	}
+
+	selected := 0
+	for _, node := range nodes {
+		if h.isSelected(node) {
+			selected++
+			node.Highlighted = true
+			if h.WithPath {
+				highlightPath(node)
+			}
+		}
+	}
+	if h.SearchField != "" {
+		// the found node counts once even if it is selected by Match or Nodes too
+		if found := h.search(root); found != nil && !h.isSelected(found) {
+			selected++
+		}
+	}
+
+	return selected
+}
+
+func (h *Highlighter) isSelected(node *PlaceableNode) bool {
+	src := BiNode(node)
+	if node.Source != nil {
+		src = node.Source
+	}
+	if h.Match != nil && h.Match(src) {
+		return true
+	}
+	for _, n := range h.Nodes {
+		if sameBiNode(n, src) {
+			return true
+		}
+	}
+	return false
+}
+
+// search walks down from root as in a binary search tree and highlights the visited path.
+// It returns the node whose field is h.SearchField, or nil if it is not found.
+func (h *Highlighter) search(root *PlaceableNode) *PlaceableNode {
+	less := h.SearchLess
+	if less == nil {
+		less = lessField
+	}
+	cur := root
+	for cur != nil {
+		cur.Highlighted = true
+		if cur.Parent != nil {
+			cur.HighlightedEdge = true
+		}
+		switch {
+		case less(h.SearchField, cur.Field):
+			cur = cur.Left
+		case less(cur.Field, h.SearchField):
+			cur = cur.Right
+		default:
+			return cur
+		}
+	}
+	return nil
+}
+
+// highlightPath highlights the nodes and edges on the path from the root to node.
+func highlightPath(node *PlaceableNode) {
+	for node.Parent != nil {
+		node.HighlightedEdge = true
+		node = node.Parent
+		node.Highlighted = true
+	}
+}
+
+// lessField compares two fields numerically if both of them are numbers, otherwise lexicographically.
+func lessField(a, b string) bool {
+	fa, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	fb, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if errA == nil && errB == nil {
+		return fa < fb
+	}
+	return a < b
+}
@@ -0,0 +1,130 @@
+package bitreevis_test
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+func newBstForTest() *myNode {
+	node1 := &myNode{Value: 1}
+	node3 := &myNode{Value: 3}
+	node2 := &myNode{Value: 2, Left: node1, Right: node3}
+	node6 := &myNode{Value: 6}
+	node9 := &myNode{Value: 9}
+	node8 := &myNode{Value: 8, Right: node9}
+	node7 := &myNode{Value: 7, Left: node6, Right: node8}
+	return &myNode{Value: 5, Left: node2, Right: node7}
+}
+
+func collectHighlighted(root *bitreevis.PlaceableNode) []string {
+	fields := make([]string, 0)
+	for _, node := range root.CollectNodes() {
+		if node.Highlighted {
+			fields = append(fields, node.Field)
+		}
+	}
+	return fields
+}
+
+func TestHighlighter_Search(t *testing.T) {
+	pRoot := bitreevis.NewPlaceableTreeFromBiNode(newBstForTest())
+
+	n := bitreevis.HighlightSearch("9").Apply(pRoot)
+	require.Equal(t, 1, n)
+	require.Equal(t, []string{"5", "7", "8", "9"}, collectHighlighted(pRoot))
+	require.False(t, pRoot.HighlightedEdge)
+	require.True(t, pRoot.Right.HighlightedEdge)
+	require.False(t, pRoot.Left.HighlightedEdge)
+
+	// searching a missing field still highlights the visited path
+	n = bitreevis.HighlightSearch("4").Apply(pRoot)
+	require.Equal(t, 0, n)
+	require.Equal(t, []string{"2", "3", "5"}, collectHighlighted(pRoot))
+}
+
+func TestHighlighter_NodesAndPredicate(t *testing.T) {
+	root := newBstForTest()
+	pRoot := bitreevis.NewPlaceableTreeFromBiNode(root)
+
+	n := bitreevis.HighlightNodes(root.Left.Left).Apply(pRoot)
+	require.Equal(t, 1, n)
+	require.Equal(t, []string{"1", "2", "5"}, collectHighlighted(pRoot))
+
+	h := &bitreevis.Highlighter{Match: func(node bitreevis.BiNode) bool {
+		return node.GetField() == "6" || node.GetField() == "3"
+	}}
+	n = h.Apply(pRoot)
+	require.Equal(t, 2, n)
+	require.Equal(t, []string{"3", "6"}, collectHighlighted(pRoot))
+}
+
+// sliceNode is a node of a complete binary tree stored in a slice in level order.
+// It is a value holding a slice, which can be neither hashed nor compared with ==.
+type sliceNode struct {
+	values []int
+	index  int
+}
+
+func (n sliceNode) GetField() string {
+	return strconv.Itoa(n.values[n.index])
+}
+
+func (n sliceNode) child(index int) bitreevis.BiNode {
+	if index >= len(n.values) {
+		return nil
+	}
+	return sliceNode{values: n.values, index: index}
+}
+
+func (n sliceNode) GetLeftChild() bitreevis.BiNode {
+	return n.child(2*n.index + 1)
+}
+
+func (n sliceNode) GetRightChild() bitreevis.BiNode {
+	return n.child(2*n.index + 2)
+}
+
+func TestHighlighter_UncomparableNodesAndOverlap(t *testing.T) {
+	root := sliceNode{values: []int{5, 2, 7, 1, 3, 6, 8}}
+	pRoot := bitreevis.NewPlaceableTreeFromBiNode(root)
+
+	var n int
+	h := &bitreevis.Highlighter{Nodes: []bitreevis.BiNode{root.GetLeftChild()}, SearchField: "3"}
+	require.NotPanics(t, func() { n = h.Apply(pRoot) })
+	require.Equal(t, 1, n)
+	require.Equal(t, []string{"2", "3", "5"}, collectHighlighted(pRoot))
+
+	// a node selected both by Match and by SearchField is counted once
+	h = &bitreevis.Highlighter{Match: func(node bitreevis.BiNode) bool {
+		return node.GetField() == "6" || node.GetField() == "3"
+	}, SearchField: "6"}
+	require.Equal(t, 2, h.Apply(pRoot))
+}
+
+func TestSvgRenderer_Highlight(t *testing.T) {
+	pRoot := bitreevis.NewPlaceableTreeFromBiNode(newBstForTest())
+	opt := bitreevis.RenderOption{
+		SiblingSeparation: 20,
+		LevelSeparation:   20,
+		NodeRadius:        20,
+		EdgeWithArrow:     true,
+		Highlight:         bitreevis.HighlightSearch("6"),
+		HighlightColor:    "orange",
+	}
+	pRoot = bitreevis.LayoutWithOption(pRoot, &opt)
+
+	result := bitreevis.NewSvgRenderer().Render(pRoot, &opt)
+	require.Nil(t, result.Error())
+	content, err := io.ReadAll(result.GetContent())
+	require.Nil(t, err)
+	svg := string(content)
+	require.Contains(t, svg, "stroke:orange")
+	require.Contains(t, svg, "opacity:0.300")
+	require.Equal(t, 2, strings.Count(svg, "self-defined-highlight-arrow-marker)"))
+}
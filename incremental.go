@@ -0,0 +1,336 @@
+package bitreevis
+
+import "math"
+
+// IncrementalLayout is the Reingold-Tilford layout of a tree of PlaceableNode which is updated as the tree changes,
+// for example one insertion at a time in a visual debugger.
+//
+// PerformLayout threads the contours of subtrees through their nodes and discards them when it finishes.
+// IncrementalLayout keeps the contours of every subtree aside instead, so that after a change only the contours
+// of the changed subtrees and of their ancestors are computed again. The coordinates are the same as those
+// calculated by PerformLayout.
+type IncrementalLayout struct {
+	root                                          *PlaceableNode
+	siblingSeparation, nodeWidth, levelSeparation int
+	states                                        map[*PlaceableNode]*incrementalState
+}
+
+// incrementalState is the state of the Reingold-Tilford algorithm kept for a node.
+type incrementalState struct {
+	// offset is the horizontal distance from the node to each of its children.
+	offset float32
+	// lmost and rmost are the extremes of the subtree, their levels are relative to the node.
+	lmost, rmost extreme
+	// threadLeft, threadRight and threadOffset continue the contour below the node if it is a leaf,
+	// as the threads of PerformLayout.
+	threadLeft, threadRight *PlaceableNode
+	threadOffset            float32
+	// threaded is the leaf on which the thread made when merging the subtrees of the node is kept, nil if none.
+	threaded *PlaceableNode
+	// left and right are the children of the node when it was last merged.
+	left, right *PlaceableNode
+}
+
+// NewIncrementalLayout lays out the tree rooted at root as PerformLayout does, and keeps the state of the layout
+// for later updates.
+//
+// siblingSeparation is the minimum gap between two nodes on the same level, nodeWidth is the radius of nodes,
+// levelSeparation is the gap between two levels. The cousin separation, the gaps per depth and the compact mode
+// of PerformLayoutWithSpacing are not supported.
+func NewIncrementalLayout(root *PlaceableNode, siblingSeparation, nodeWidth, levelSeparation int) *IncrementalLayout {
+	l := &IncrementalLayout{
+		siblingSeparation: siblingSeparation + nodeWidth*2,
+		nodeWidth:         nodeWidth,
+		levelSeparation:   levelSeparation,
+		states:            make(map[*PlaceableNode]*incrementalState),
+	}
+	l.Update(root, root)
+	return l
+}
+
+// Root returns the root of the tree laid out.
+func (l *IncrementalLayout) Root() *PlaceableNode {
+	return l.root
+}
+
+// Update lays out the tree rooted at root again after it changed, and returns the nodes whose coordinates changed,
+// including the nodes new to the layout. root may differ from the previous root, for example after a rotation.
+//
+// changed must contain every node whose Left or Right was changed since the last layout. The Parent of every node
+// on the paths from changed to root must be up to date. Subtrees made of nodes new to the layout are laid out
+// entirely, the other subtrees keep their contours unless they contain a changed node.
+// Removed nodes need not be in changed, their states are dropped.
+func (l *IncrementalLayout) Update(root *PlaceableNode, changed ...*PlaceableNode) []*PlaceableNode {
+	oldRoot := l.root
+	l.root = root
+	if root == nil {
+		l.states = make(map[*PlaceableNode]*incrementalState)
+		return nil
+	}
+
+	// the nodes whose contours are computed again: changed nodes, their ancestors and new nodes
+	dirty := make(map[*PlaceableNode]bool)
+	added := make(map[*PlaceableNode]bool)
+	var stack []*PlaceableNode
+	for _, node := range changed {
+		if node == nil {
+			continue
+		}
+		stack = append(stack, node)
+		for p := node.Parent; p != nil && !dirty[p]; p = p.Parent {
+			dirty[p] = true
+		}
+	}
+	// root is always merged again, it may be a new root
+	stack = append(stack, root)
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		dirty[node] = true
+		for _, child := range []*PlaceableNode{node.Left, node.Right} {
+			if child != nil && l.states[child] == nil && !dirty[child] {
+				stack = append(stack, child)
+			}
+		}
+	}
+
+	// the threads made by merging dirty nodes may no longer be on the contours
+	for node := range dirty {
+		if l.states[node] == nil {
+			added[node] = true
+			l.states[node] = &incrementalState{}
+			continue
+		}
+		l.unthread(node)
+	}
+	l.remove(root, oldRoot, dirty)
+
+	l.setup(root, dirty)
+	return l.petrify(root, dirty, added)
+}
+
+// unthread removes the thread made when merging the subtrees of node.
+func (l *IncrementalLayout) unthread(node *PlaceableNode) {
+	s := l.states[node]
+	if s.threaded != nil {
+		if t := l.states[s.threaded]; t != nil {
+			t.threadLeft, t.threadRight, t.threadOffset = nil, nil, 0
+		}
+		s.threaded = nil
+	}
+}
+
+// remove drops the states of the nodes removed from the tree rooted at root, and the threads they made on the
+// subtrees still in the tree. A node is removed when it was a child of a dirty node, or oldRoot, and it is now
+// neither the child of a dirty node nor root, since attaching a node elsewhere changes its new parent.
+// The removed nodes are walked along the children they had when they were merged.
+func (l *IncrementalLayout) remove(root, oldRoot *PlaceableNode, dirty map[*PlaceableNode]bool) {
+	attached := map[*PlaceableNode]bool{root: true}
+	var stack []*PlaceableNode
+	if oldRoot != nil {
+		stack = append(stack, oldRoot)
+	}
+	for node := range dirty {
+		attached[node.Left], attached[node.Right] = true, true
+		s := l.states[node]
+		if s.left != node.Left && s.left != nil {
+			stack = append(stack, s.left)
+		}
+		if s.right != node.Right && s.right != nil {
+			stack = append(stack, s.right)
+		}
+	}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		s := l.states[node]
+		if s == nil || attached[node] {
+			continue
+		}
+		l.unthread(node)
+		delete(l.states, node)
+		if s.left != nil {
+			stack = append(stack, s.left)
+		}
+		if s.right != nil {
+			stack = append(stack, s.right)
+		}
+	}
+}
+
+// setup performs the post-order pass of the Reingold-Tilford algorithm on the dirty nodes of the tree rooted at root.
+// The subtrees which are not dirty keep their states.
+func (l *IncrementalLayout) setup(root *PlaceableNode, dirty map[*PlaceableNode]bool) {
+	type frame struct {
+		node *PlaceableNode
+		done bool
+	}
+	stack := []frame{{node: root}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.done {
+			l.merge(top.node)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		top.done = true
+		node := top.node
+		for _, child := range []*PlaceableNode{node.Right, node.Left} {
+			if child != nil && dirty[child] {
+				stack = append(stack, frame{node: child})
+			}
+		}
+	}
+}
+
+// contourLeft, contourRight and contourOffset walk the contours of subtrees: the children of a node,
+// or the threads of a leaf, with the offset to them.
+func (l *IncrementalLayout) contourLeft(node *PlaceableNode) *PlaceableNode {
+	if node.IsLeaf() {
+		return l.states[node].threadLeft
+	}
+	return node.Left
+}
+
+func (l *IncrementalLayout) contourRight(node *PlaceableNode) *PlaceableNode {
+	if node.IsLeaf() {
+		return l.states[node].threadRight
+	}
+	return node.Right
+}
+
+func (l *IncrementalLayout) contourOffset(node *PlaceableNode) float32 {
+	if node.IsLeaf() {
+		return l.states[node].threadOffset
+	}
+	return l.states[node].offset
+}
+
+// merge places the two subtrees of root as close as possible and records the extremes of the whole subtree,
+// as layoutMerge does with the contours kept aside.
+func (l *IncrementalLayout) merge(root *PlaceableNode) {
+	s := l.states[root]
+	left, right := root.Left, root.Right
+	s.left, s.right = left, right
+	if left == nil && right == nil {
+		s.offset = 0
+		s.lmost = extreme{addr: root}
+		s.rmost = extreme{addr: root}
+		return
+	}
+
+	// the extremes of a missing subtree are at level -1.
+	// As in layoutSetup, ll and rl receive the rightmost extremes of the subtrees, lr and rr the leftmost ones.
+	ll, lr := extreme{level: -1}, extreme{level: -1}
+	rl, rr := extreme{level: -1}, extreme{level: -1}
+	if left != nil {
+		ll, lr = l.states[left].rmost, l.states[left].lmost
+	}
+	if right != nil {
+		rl, rr = l.states[right].rmost, l.states[right].lmost
+	}
+
+	siblingSeparation := float32(l.siblingSeparation)
+	currSep := siblingSeparation
+	rootSep := siblingSeparation
+	var lOffSum, rOffSum float32 = 0.0, 0.0
+
+	lc, rc := left, right
+	for lc != nil && rc != nil {
+		if currSep < siblingSeparation {
+			rootSep += siblingSeparation - currSep
+			currSep = siblingSeparation
+		}
+		if next := l.contourRight(lc); next != nil {
+			lOffSum += l.contourOffset(lc)
+			currSep -= l.contourOffset(lc)
+			lc = next
+		} else {
+			lOffSum -= l.contourOffset(lc)
+			currSep += l.contourOffset(lc)
+			lc = l.contourLeft(lc)
+		}
+
+		if next := l.contourLeft(rc); next != nil {
+			rOffSum -= l.contourOffset(rc)
+			currSep -= l.contourOffset(rc)
+			rc = next
+		} else {
+			rOffSum += l.contourOffset(rc)
+			currSep += l.contourOffset(rc)
+			rc = l.contourRight(rc)
+		}
+	}
+	s.offset = (rootSep + float32(l.nodeWidth)) / 2
+	lOffSum -= s.offset
+	rOffSum += s.offset
+
+	if rl.level > ll.level || left == nil {
+		s.lmost = extreme{addr: rl.addr, level: rl.level + 1, offset: rl.offset + s.offset}
+	} else {
+		s.lmost = extreme{addr: ll.addr, level: ll.level + 1, offset: ll.offset - s.offset}
+	}
+	if lr.level > rr.level || right == nil {
+		s.rmost = extreme{addr: lr.addr, level: lr.level + 1, offset: lr.offset - s.offset}
+	} else {
+		s.rmost = extreme{addr: rr.addr, level: rr.level + 1, offset: rr.offset + s.offset}
+	}
+
+	// the contour of the shorter subtree goes on along the contour of the taller one
+	if lc != nil && lc != left {
+		t := l.states[rr.addr]
+		t.threadOffset = float32(math.Abs(float64(rr.offset) + float64(s.offset) - float64(lOffSum)))
+		if (lOffSum - s.offset) <= rr.offset {
+			t.threadLeft = lc
+		} else {
+			t.threadRight = lc
+		}
+		s.threaded = rr.addr
+	} else if rc != nil && rc != right {
+		t := l.states[ll.addr]
+		t.threadOffset = float32(math.Abs(float64(ll.offset) - float64(s.offset) - float64(rOffSum)))
+		if (rOffSum + s.offset) >= ll.offset {
+			t.threadRight = rc
+		} else {
+			t.threadLeft = rc
+		}
+		s.threaded = ll.addr
+	}
+}
+
+// petrify converts the offsets of the tree rooted at root into coordinates with root at (0, 0), and returns
+// the nodes whose coordinates changed and the added nodes. A subtree which is not dirty and whose root
+// keeps its coordinates and depth is skipped, since none of its nodes moves.
+func (l *IncrementalLayout) petrify(root *PlaceableNode, dirty, added map[*PlaceableNode]bool) []*PlaceableNode {
+	type petrifyItem struct {
+		node  *PlaceableNode
+		x     float32
+		depth int
+	}
+	levelHeight := float32(l.nodeWidth*2 + l.levelSeparation)
+	var moved []*PlaceableNode
+	stack := []petrifyItem{{node: root}}
+	for len(stack) > 0 {
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := item.node
+		y := float32(item.depth) * levelHeight
+		same := node.X == item.x && node.Y == y && node.Depth == item.depth
+		if same && !dirty[node] {
+			continue
+		}
+		if !same || added[node] {
+			moved = append(moved, node)
+		}
+		node.X, node.Y, node.Depth = item.x, y, item.depth
+		node.Offset = l.states[node].offset
+
+		if node.Right != nil {
+			stack = append(stack, petrifyItem{node: node.Right, x: item.x + node.Offset, depth: item.depth + 1})
+		}
+		if node.Left != nil {
+			stack = append(stack, petrifyItem{node: node.Left, x: item.x - node.Offset, depth: item.depth + 1})
+		}
+	}
+	return moved
+}
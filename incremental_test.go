@@ -0,0 +1,171 @@
+package bitreevis_test
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+// bstInsert inserts a node of value into the binary search tree rooted at root, and returns the new node.
+func bstInsert(root *bitreevis.PlaceableNode, value int) *bitreevis.PlaceableNode {
+	node := bitreevis.NewPlaceableNode(strconv.Itoa(value))
+	for cur := root; ; {
+		v, _ := strconv.Atoi(cur.Field)
+		next := &cur.Right
+		if value < v {
+			next = &cur.Left
+		}
+		if *next == nil {
+			*next = node
+			node.Parent = cur
+			return node
+		}
+		cur = *next
+	}
+}
+
+// bstDelete removes node from the binary search tree rooted at root, and returns the new root and the nodes
+// whose children were changed.
+func bstDelete(root, node *bitreevis.PlaceableNode) (*bitreevis.PlaceableNode, []*bitreevis.PlaceableNode) {
+	var changed []*bitreevis.PlaceableNode
+	replacement := node.Left
+	if node.Left == nil {
+		replacement = node.Right
+	} else if node.Right != nil {
+		// the successor of node takes its place
+		replacement = node.Right
+		for replacement.Left != nil {
+			replacement = replacement.Left
+		}
+		if parent := replacement.Parent; parent != node {
+			parent.Left = replacement.Right
+			if parent.Left != nil {
+				parent.Left.Parent = parent
+			}
+			replacement.Right = node.Right
+			replacement.Right.Parent = replacement
+			changed = append(changed, parent)
+		}
+		replacement.Left = node.Left
+		replacement.Left.Parent = replacement
+		changed = append(changed, replacement)
+	}
+
+	if replacement != nil {
+		replacement.Parent = node.Parent
+	}
+	switch parent := node.Parent; {
+	case parent == nil:
+		root = replacement
+	case parent.Left == node:
+		parent.Left = replacement
+		changed = append(changed, parent)
+	default:
+		parent.Right = replacement
+		changed = append(changed, parent)
+	}
+	return root, changed
+}
+
+// cloneShape returns a new tree of the same shape and fields as the tree rooted at root.
+func cloneShape(root *bitreevis.PlaceableNode) *bitreevis.PlaceableNode {
+	if root == nil {
+		return nil
+	}
+	clone := bitreevis.NewPlaceableNode(root.Field)
+	clone.Left, clone.Right = cloneShape(root.Left), cloneShape(root.Right)
+	return clone
+}
+
+// requireSameLayout checks that the tree rooted at root is placed as PerformLayout places it.
+func requireSameLayout(t *testing.T, root *bitreevis.PlaceableNode) {
+	expected := bitreevis.PerformLayout(cloneShape(root), 10, 10, 10).CollectNodes()
+	actual := root.CollectNodes()
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		require.Equal(t, expected[i].X, actual[i].X, actual[i].Field)
+		require.Equal(t, expected[i].Y, actual[i].Y, actual[i].Field)
+	}
+}
+
+func TestIncrementalLayout_Insert(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	root := bitreevis.NewPlaceableNode("500")
+	l := bitreevis.NewIncrementalLayout(root, 10, 10, 10)
+	requireSameLayout(t, root)
+
+	for i := 0; i < 300; i++ {
+		before := make(map[*bitreevis.PlaceableNode][2]float32)
+		for _, node := range root.CollectNodes() {
+			before[node] = [2]float32{node.X, node.Y}
+		}
+
+		node := bstInsert(root, rnd.Intn(1000))
+		moved := l.Update(root, node.Parent)
+		requireSameLayout(t, root)
+
+		// exactly the new node and the nodes whose coordinates changed are reported
+		expected := map[*bitreevis.PlaceableNode]bool{node: true}
+		for _, n := range root.CollectNodes() {
+			if pos, ok := before[n]; ok && pos != [2]float32{n.X, n.Y} {
+				expected[n] = true
+			}
+		}
+		actual := make(map[*bitreevis.PlaceableNode]bool)
+		for _, n := range moved {
+			actual[n] = true
+		}
+		require.Equal(t, expected, actual)
+	}
+}
+
+func TestIncrementalLayout_Rotate(t *testing.T) {
+	root := bitreevis.NewPlaceableNode("50")
+	for _, v := range []int{30, 70, 20, 40, 60, 80, 10, 25, 35, 45, 5} {
+		bstInsert(root, v)
+	}
+	l := bitreevis.NewIncrementalLayout(root, 10, 10, 10)
+
+	// rotate the left subtree of the root right: 30 goes down and 20 goes up
+	x := root.Left
+	y := x.Left
+	x.Left, y.Right = y.Right, x
+	x.Left.Parent, x.Parent, y.Parent = x, y, root
+	root.Left = y
+	moved := l.Update(root, x, y, root)
+	requireSameLayout(t, root)
+	require.Contains(t, moved, x)
+
+	// rotate the whole tree left, the root changes
+	newRoot := root.Right
+	root.Right, newRoot.Left = newRoot.Left, root
+	root.Right.Parent, root.Parent, newRoot.Parent = root, newRoot, nil
+	l.Update(newRoot, root, newRoot)
+	require.Same(t, newRoot, l.Root())
+	requireSameLayout(t, newRoot)
+	require.Equal(t, 1, root.Depth)
+}
+
+func TestIncrementalLayout_InsertDelete(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	root := bitreevis.NewPlaceableNode("500")
+	l := bitreevis.NewIncrementalLayout(root, 10, 10, 10)
+
+	for i := 0; i < 1000; i++ {
+		nodes := root.CollectNodes()
+		if len(nodes) > 1 && rnd.Intn(5) < 2 {
+			var changed []*bitreevis.PlaceableNode
+			root, changed = bstDelete(root, nodes[rnd.Intn(len(nodes))])
+			l.Update(root, changed...)
+		} else {
+			node := bstInsert(root, rnd.Intn(1000))
+			l.Update(root, node.Parent)
+		}
+		require.Same(t, root, l.Root())
+		requireSameLayout(t, root)
+	}
+}
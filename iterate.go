@@ -1,13 +1,11 @@
 package bitreevis
 
-// CalHeight calculates the height of a binary tree in a recursive manner.
+// CalHeight calculates the height of a binary tree level by level.
 //
 // If root does not have any children, CalHeight returns 1. If root is nil, returns 0.
 func CalHeight(root BiNode) int {
-	if BiNodeIsNil(root) {
-		return 0
-	}
-	return maxInt(CalHeight(root.GetLeftChild()), CalHeight(root.GetRightChild())) + 1
+	_, height := countNodes(root)
+	return height
 }
 
 // CollectNodeByLevelOrder collects all nodes in a binary tree in level order.
@@ -26,7 +24,7 @@ func CollectNodeByLevelOrder(root BiNode) [][]BiNode {
 		level := make([]BiNode, 0, n)
 		for i := 0; i < n; i++ {
 			cur := nodes[0]
-			if !BiNodeIsNil(cur) {
+			if !biNodeIsNil(cur) {
 				level = append(level, cur)
 				nodes = append(nodes, cur.GetLeftChild())
 				nodes = append(nodes, cur.GetRightChild())
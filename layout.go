@@ -4,133 +4,341 @@ import (
 	"math"
 )
 
-func layoutSetup(root *PlaceableNode, level int, lmost, rmost *extreme, siblingSeparation, nodeWidth, levelSeparation int) {
-	var l, r *PlaceableNode
-	ll := &extreme{}
-	lr := &extreme{}
-	rl := &extreme{}
-	rr := &extreme{}
+// LayoutAlgorithm specifies how the coordinates of nodes are calculated.
+type LayoutAlgorithm int
 
-	var currSep, rootSep, lOffSum, rOffSum float32 = 0.0, 0.0, 0.0, 0.0
+const (
+	// LayoutTidy places nodes with the algorithm of Tidier Drawings of Trees by Reingold and Tilford,
+	// which gives compact and symmetric drawings.
+	LayoutTidy LayoutAlgorithm = iota
+	// LayoutInOrder places every node at the horizontal position of its in-order rank as in the layout of Knuth,
+	// so that the keys of a binary search tree are sorted from left to right and no two nodes share a column.
+	LayoutInOrder
+	// LayoutHeap places every node at its slot in a complete binary tree, as in the array of a binary heap.
+	// Trees whose drawings would be wider than MaxSlotLayoutWidth are placed as with LayoutTidy.
+	LayoutHeap
+	// LayoutRadial places the root at the centre and every level on a circle around it.
+	LayoutRadial
+	// LayoutHTree places nodes as in an H-tree, which is a compact drawing of complete binary trees.
+	// Trees whose drawings would be wider or taller than MaxSlotLayoutWidth are placed as with LayoutTidy.
+	LayoutHTree
+)
 
-	if root == nil {
-		lmost.level = -1
-		rmost.level = -1
+// layoutFrame is a frame of the explicit stack used by layoutSetup.
+type layoutFrame struct {
+	root  *PlaceableNode
+	level int
+	// lmost and rmost are the indices of the extremes to be filled in for the subtree.
+	lmost, rmost int
+	// ext is the index of the extremes ll, lr, rl and rr of the children, which are stored consecutively.
+	ext int
+	// state records how far the frame has been processed:
+	// 0 for entering, 1 for left subtree done, 2 for both subtrees done.
+	state int
+	// y is the vertical coordinate of the level of root.
+	y float32
+}
+
+// Spacing specifies the spacing of the Reingold-Tilford layout. The values are in the units of the graphic
+// and may be fractional.
+type Spacing struct {
+	// NodeRadius is the radius of nodes.
+	NodeRadius float32
+	// Sibling is the minimum gap between two nodes which have the same parent.
+	Sibling float32
+	// Cousin is the minimum gap between two nodes on the same level which have different parents, as in the
+	// algorithm of Walker. A Cousin larger than Sibling keeps subtrees apart from each other.
+	Cousin float32
+	// Level is the gap between two levels.
+	Level float32
+	// LevelAt returns the gap between the levels at depth and depth+1. If not nil, it is used in place of Level.
+	LevelAt func(depth int) float32
+	// Compact specifies whether subtrees nest under the contours of each other more tightly. Two subtrees
+	// are placed exactly the separation apart instead of one more radius apart, and a lone child is placed
+	// one radius aside from its parent.
+	Compact bool
+}
+
+// LevelSeparations returns a function for Spacing.LevelAt and RenderOption.LevelSeparationAt which gives
+// gaps[depth] as the gap below depth. The last gap is used for the deeper levels. If gaps is empty, the
+// function returns 0.
+func LevelSeparations(gaps ...float32) func(depth int) float32 {
+	return func(depth int) float32 {
+		if len(gaps) == 0 {
+			return 0
+		}
+		if depth >= len(gaps) {
+			return gaps[len(gaps)-1]
+		}
+		return gaps[depth]
+	}
+}
+
+// layoutSpacing is the spacing used by layoutSetup and layoutMerge.
+type layoutSpacing struct {
+	// sibling and cousin are the minimum distances between the centres of nodes.
+	sibling, cousin float32
+	nodeWidth       float32
+	compact         bool
+	level           float32
+	levelAt         func(depth int) float32
+}
+
+func newLayoutSpacing(sp Spacing) *layoutSpacing {
+	return &layoutSpacing{
+		sibling:   sp.Sibling + sp.NodeRadius*2,
+		cousin:    sp.Cousin + sp.NodeRadius*2,
+		nodeWidth: sp.NodeRadius,
+		compact:   sp.Compact,
+		level:     sp.Level,
+		levelAt:   sp.LevelAt,
+	}
+}
+
+// childY returns the vertical coordinate of the level below the level at depth, whose coordinate is y.
+func (sp *layoutSpacing) childY(depth int, y float32) float32 {
+	if sp.levelAt == nil {
+		return float32(depth+1) * (sp.nodeWidth*2 + sp.level)
+	}
+	return y + sp.nodeWidth*2 + sp.levelAt(depth)
+}
+
+// layoutSetup performs the post-order pass of the Reingold-Tilford algorithm.
+//
+// It uses an explicit stack instead of recursion, so that degenerate trees with millions of levels can be handled.
+// The extremes of the frames are kept in a stack alongside, so that no allocation is needed for each node.
+func layoutSetup(root *PlaceableNode, level int, lmost, rmost *extreme, sp *layoutSpacing) {
+	extremes := make([]extreme, 2, 2+4*16)
+	stack := []layoutFrame{{root: root, level: level, lmost: 0, rmost: 1, y: root.Y}}
+	for len(stack) > 0 {
+		top := len(stack) - 1
+		frame := &stack[top]
+		if frame.root == nil {
+			extremes[frame.lmost].level = -1
+			extremes[frame.rmost].level = -1
+			stack = stack[:top]
+			continue
+		}
+
+		switch frame.state {
+		case 0:
+			frame.root.Y = frame.y
+			frame.ext = len(extremes)
+			extremes = append(extremes, extreme{}, extreme{}, extreme{}, extreme{})
+			frame.state = 1
+			stack = append(stack, layoutFrame{root: frame.root.Left, level: frame.level + 1, lmost: frame.ext + 1, rmost: frame.ext,
+				y: sp.childY(frame.level, frame.y)})
+		case 1:
+			frame.state = 2
+			stack = append(stack, layoutFrame{root: frame.root.Right, level: frame.level + 1, lmost: frame.ext + 3, rmost: frame.ext + 2,
+				y: sp.childY(frame.level, frame.y)})
+		default:
+			ext := extremes[frame.ext : frame.ext+4]
+			layoutMerge(frame.root, frame.level, &extremes[frame.lmost], &extremes[frame.rmost],
+				&ext[0], &ext[1], &ext[2], &ext[3], sp)
+			extremes = extremes[:frame.ext]
+			stack = stack[:top]
+		}
+	}
+	*lmost, *rmost = extremes[0], extremes[1]
+}
+
+// layoutMerge places the two subtrees of root as close as possible and records the extremes of the whole subtree.
+//
+// The children of root are placed at least the sibling separation apart, and the nodes below them at least
+// the cousin separation apart.
+func layoutMerge(root *PlaceableNode, level int, lmost, rmost, ll, lr, rl, rr *extreme, sp *layoutSpacing) {
+	l, r := root.Left, root.Right
+
+	if r == nil && l == nil {
+		rmost.addr = root
+		lmost.addr = root
+		rmost.level = level
+		lmost.level = level
+		rmost.offset = 0
+		lmost.offset = 0
+		root.Offset = 0
+		return
+	}
+
+	currSep := sp.sibling
+	rootSep := sp.sibling
+	minSep := sp.sibling
+	var lOffSum, rOffSum float32 = 0.0, 0.0
+
+	for l != nil && r != nil {
+		if currSep < minSep {
+			rootSep += minSep - currSep
+			currSep = minSep
+		}
+		minSep = sp.cousin
+		if l.Right != nil {
+			lOffSum += l.Offset
+			currSep -= l.Offset
+			l = l.Right
+		} else {
+			lOffSum -= l.Offset
+			currSep += l.Offset
+			l = l.Left
+		}
+
+		if r.Left != nil {
+			rOffSum -= r.Offset
+			currSep -= r.Offset
+			r = r.Left
+		} else {
+			rOffSum += r.Offset
+			currSep += r.Offset
+			r = r.Right
+		}
+	}
+	switch {
+	case !sp.compact:
+		root.Offset = (rootSep + sp.nodeWidth) / 2
+	case root.Left == nil || root.Right == nil:
+		root.Offset = sp.nodeWidth
+	default:
+		root.Offset = rootSep / 2
+	}
+	lOffSum -= root.Offset
+	rOffSum += root.Offset
+
+	if rl.level > ll.level || root.Left == nil {
+		lmost.addr = rl.addr
+		lmost.level = rl.level
+		lmost.offset = rl.offset
+		lmost.offset += root.Offset
+	} else {
+		lmost.addr = ll.addr
+		lmost.level = ll.level
+		lmost.offset = ll.offset
+		lmost.offset -= root.Offset
+	}
+
+	if lr.level > rr.level || root.Right == nil {
+		rmost.addr = lr.addr
+		rmost.level = lr.level
+		rmost.offset = lr.offset
+		rmost.offset -= root.Offset
 	} else {
-		root.Y = float32(level) * float32(nodeWidth*2+levelSeparation)
-		l = root.Left
-		r = root.Right
-		layoutSetup(l, level+1, lr, ll, siblingSeparation, nodeWidth, levelSeparation)
-		layoutSetup(r, level+1, rr, rl, siblingSeparation, nodeWidth, levelSeparation)
-		if r == nil && l == nil {
-			rmost.addr = root
-			lmost.addr = root
-			rmost.level = level
-			lmost.level = level
-			rmost.offset = 0
-			lmost.offset = 0
-			root.Offset = 0
+		rmost.addr = rr.addr
+		rmost.level = rr.level
+		rmost.offset = rr.offset
+		rmost.offset += root.Offset
+	}
+
+	if l != nil && l != root.Left {
+		rr.addr.Thread = true
+		rr.addr.Offset = float32(math.Abs(float64(rr.offset) + float64(root.Offset) - float64(lOffSum)))
+		if (lOffSum - root.Offset) <= rr.offset {
+			rr.addr.Left = l
+		} else {
+			rr.addr.Right = l
+		}
+	} else if r != nil && r != root.Right {
+		ll.addr.Thread = true
+		ll.addr.Offset = float32(math.Abs(float64(ll.offset) - float64(root.Offset) - float64(rOffSum)))
+		if (rOffSum + root.Offset) >= ll.offset {
+			ll.addr.Right = r
 		} else {
-			currSep = float32(siblingSeparation)
-			rootSep = float32(siblingSeparation)
-			lOffSum = 0
-			rOffSum = 0
-
-			for l != nil && r != nil {
-				if currSep < float32(siblingSeparation) {
-					rootSep += float32(siblingSeparation) - currSep
-					currSep = float32(siblingSeparation)
-				}
-				if l.Right != nil {
-					lOffSum += l.Offset
-					currSep -= l.Offset
-					l = l.Right
-				} else {
-					lOffSum -= l.Offset
-					currSep += l.Offset
-					l = l.Left
-				}
-
-				if r.Left != nil {
-					rOffSum -= r.Offset
-					currSep -= r.Offset
-					r = r.Left
-				} else {
-					rOffSum += r.Offset
-					currSep += r.Offset
-					r = r.Right
-				}
-			}
-			root.Offset = (rootSep + float32(nodeWidth)) / 2
-			lOffSum -= root.Offset
-			rOffSum += root.Offset
-
-			if rl.level > ll.level || root.Left == nil {
-				lmost.addr = rl.addr
-				lmost.level = rl.level
-				lmost.offset = rl.offset
-				lmost.offset += root.Offset
-			} else {
-				lmost.addr = ll.addr
-				lmost.level = ll.level
-				lmost.offset = ll.offset
-				lmost.offset -= root.Offset
-			}
-
-			if lr.level > rr.level || root.Right == nil {
-				rmost.addr = lr.addr
-				rmost.level = lr.level
-				rmost.offset = lr.offset
-				rmost.offset -= root.Offset
-			} else {
-				rmost.addr = rr.addr
-				rmost.level = rr.level
-				rmost.offset = rr.offset
-				rmost.offset += root.Offset
-			}
-
-			if l != nil && l != root.Left {
-				rr.addr.Thread = true
-				rr.addr.Offset = float32(math.Abs(float64(rr.offset) + float64(root.Offset) - float64(lOffSum)))
-				if (lOffSum - root.Offset) <= rr.offset {
-					rr.addr.Left = l
-				} else {
-					rr.addr.Right = l
-				}
-			} else if r != nil && r != root.Right {
-				ll.addr.Thread = true
-				ll.addr.Offset = float32(math.Abs(float64(ll.offset) - float64(root.Offset) - float64(rOffSum)))
-				if (rOffSum + root.Offset) >= ll.offset {
-					ll.addr.Right = r
-				} else {
-					ll.addr.Left = r
-				}
-			}
+			ll.addr.Left = r
 		}
 	}
 }
 
+// layoutPetrify performs the pre-order pass of the Reingold-Tilford algorithm, converting offsets into absolute coordinates.
 func layoutPetrify(root *PlaceableNode, xPos float32) {
-	if root != nil {
-		root.X = float32(xPos)
-		if root.Thread {
-			root.Thread = false
-			root.Left = nil
-			root.Right = nil
+	type petrifyItem struct {
+		node *PlaceableNode
+		xPos float32
+	}
+	stack := []petrifyItem{{node: root, xPos: xPos}}
+	for len(stack) > 0 {
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if item.node == nil {
+			continue
 		}
-		layoutPetrify(root.Left, xPos-root.Offset)
-		layoutPetrify(root.Right, xPos+root.Offset)
+		node := item.node
+		node.X = item.xPos
+		if node.Thread {
+			node.Thread = false
+			node.Left = nil
+			node.Right = nil
+		}
+		stack = append(stack, petrifyItem{node: node.Right, xPos: item.xPos + node.Offset})
+		stack = append(stack, petrifyItem{node: node.Left, xPos: item.xPos - node.Offset})
 	}
 }
 
-func peformLayout(root *PlaceableNode, siblingSeparation, nodeWidth, levelSeparation int) *PlaceableNode {
+// PerformLayout calculates the coordinates of every node in the tree rooted at root with the algorithm
+// of Tidier Drawings of Trees. The root is placed at (0, 0).
+//
+// siblingSeparation is the minimum gap between two nodes on the same level, nodeWidth is the radius of nodes,
+// levelSeparation is the gap between two levels. If root is nil, PerformLayout returns nil.
+func PerformLayout(root *PlaceableNode, siblingSeparation, nodeWidth, levelSeparation int) *PlaceableNode {
+	return PerformLayoutWithSpacing(root, Spacing{
+		NodeRadius: float32(nodeWidth),
+		Sibling:    float32(siblingSeparation),
+		Cousin:     float32(siblingSeparation),
+		Level:      float32(levelSeparation),
+	})
+}
+
+// PerformLayoutWithSpacing calculates the coordinates of every node in the tree rooted at root with the algorithm
+// of Tidier Drawings of Trees, as PerformLayout does with the finer spacing of sp. The root is placed at (0, 0).
+// If root is nil, PerformLayoutWithSpacing returns nil.
+func PerformLayoutWithSpacing(root *PlaceableNode, sp Spacing) *PlaceableNode {
+	if root == nil {
+		return nil
+	}
+	root.Y = 0
 	lm, rm := &extreme{}, &extreme{}
-	layoutSetup(root, 0, lm, rm, siblingSeparation, nodeWidth, levelSeparation)
+	layoutSetup(root, 0, lm, rm, newLayoutSpacing(sp))
 	layoutPetrify(root, root.X)
 
 	return root
 }
 
-func PerformLayout(root *PlaceableNode, siblingSeparation, nodeWidth, levelSeparation int) *PlaceableNode {
-	return peformLayout(root, siblingSeparation+nodeWidth*2, nodeWidth, levelSeparation)
+// PerformInOrderLayout calculates the coordinates of every node in the tree rooted at root, with the horizontal
+// position of each node given by its in-order rank. The root is placed at (0, 0).
+//
+// siblingSeparation is the gap between two adjacent columns, nodeWidth is the radius of nodes,
+// levelSeparation is the gap between two levels. If root is nil, PerformInOrderLayout returns nil.
+func PerformInOrderLayout(root *PlaceableNode, siblingSeparation, nodeWidth, levelSeparation int) *PlaceableNode {
+	return layoutInOrder(root, float32(siblingSeparation+nodeWidth*2), float32(nodeWidth*2+levelSeparation))
+}
+
+// layoutInOrder places the nodes of the tree rooted at root in columns by their in-order rank and in rows
+// by their depth, with the root at (0, 0).
+func layoutInOrder(root *PlaceableNode, columnWidth, levelHeight float32) *PlaceableNode {
+	if root == nil {
+		return nil
+	}
+
+	type inOrderItem struct {
+		node  *PlaceableNode
+		level int
+	}
+	stack := make([]inOrderItem, 0, 16)
+	cur, level, rank := root, 0, 0
+	for cur != nil || len(stack) > 0 {
+		for cur != nil {
+			stack = append(stack, inOrderItem{node: cur, level: level})
+			cur, level = cur.Left, level+1
+		}
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		item.node.X = float32(rank) * columnWidth
+		item.node.Y = float32(item.level) * levelHeight
+		rank++
+		cur, level = item.node.Right, item.level+1
+	}
+
+	// shift the drawing so that the root is at x=0 as in PerformLayout
+	shift := root.X
+	for _, node := range root.CollectNodes() {
+		node.X -= shift
+	}
+	return root
 }
@@ -0,0 +1,175 @@
+package bitreevis_test
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+func TestPerformInOrderLayout(t *testing.T) {
+	opt := (&bitreevis.RenderOption{Layout: bitreevis.LayoutInOrder}).WithDefaults()
+	pRoot := bitreevis.LayoutWithOption(bitreevis.NewPlaceableTreeFromBiNode(newBstForTest()), opt)
+	require.Equal(t, float32(0), pRoot.X)
+	require.Equal(t, float32(0), pRoot.Y)
+
+	// the keys are sorted from left to right in equal steps, the root 5 is the fourth
+	step := opt.SiblingSeparation + float32(2*opt.NodeRadius)
+	levelHeight := float32(2*opt.NodeRadius) + opt.LevelSeparation
+	nodes := pRoot.CollectNodes()
+	for i, node := range nodes {
+		require.Equal(t, float32(i-3)*step, node.X, node.Field)
+		require.Equal(t, float32(node.Depth)*levelHeight, node.Y, node.Field)
+	}
+
+	require.Nil(t, bitreevis.PerformInOrderLayout(nil, 10, 10, 10))
+	err := (&bitreevis.RenderOption{Layout: bitreevis.LayoutHTree + 1}).Validate()
+	require.True(t, errors.Is(err, bitreevis.ErrInvalidOption))
+}
+
+func TestPerformInOrderLayout_DeepChain(t *testing.T) {
+	pRoot := bitreevis.NewPlaceableTreeFromBiNode(newChainForTest(deepChainLength, true))
+	pRoot = bitreevis.PerformInOrderLayout(pRoot, 10, 10, 10)
+	_, stats := pRoot.CollectNodesWithStat()
+	require.Equal(t, float32(-(deepChainLength-1)*30), stats.MinX)
+	require.Equal(t, float32((deepChainLength-1)*30), stats.MaxY)
+}
+
+func TestSvgRenderer_SortedAxis(t *testing.T) {
+	content := renderForTest(t, newBstForTest(), &bitreevis.RenderOption{Layout: bitreevis.LayoutInOrder, SortedAxis: true})
+	requireWellFormed(t, content)
+	// one projection line for each of the 8 nodes
+	require.Equal(t, 8, strings.Count(content, "stroke-dasharray:1 3"))
+	plainW, plainH := svgSize(t, renderForTest(t, newBstForTest(), &bitreevis.RenderOption{Layout: bitreevis.LayoutInOrder}))
+	w, h := svgSize(t, content)
+	require.Equal(t, plainW, w)
+	require.Greater(t, h, plainH)
+
+	content = renderForTest(t, newBstForTest(), &bitreevis.RenderOption{
+		Layout:      bitreevis.LayoutInOrder,
+		SortedAxis:  true,
+		CSSClasses:  true,
+		TightBounds: true,
+	})
+	requireWellFormed(t, content)
+	require.Equal(t, 1, strings.Count(content, `class="axis"`))
+	require.Equal(t, 8, strings.Count(content, `class="projection"`))
+	require.Equal(t, 8, strings.Count(content, `class="axis-label"`))
+}
+
+// newPlaceableCompleteTree returns a complete tree of 7 placeable nodes, and the nodes in level order.
+func newPlaceableCompleteTree() (*bitreevis.PlaceableNode, []*bitreevis.PlaceableNode) {
+	nodes := make([]*bitreevis.PlaceableNode, 7)
+	for i := range nodes {
+		nodes[i] = bitreevis.NewPlaceableNode(strconv.Itoa(i))
+		if i > 0 {
+			parent := nodes[(i-1)/2]
+			if i%2 == 1 {
+				parent.Left = nodes[i]
+			} else {
+				parent.Right = nodes[i]
+			}
+		}
+	}
+	return nodes[0], nodes
+}
+
+func TestPerformLayoutWithSpacing(t *testing.T) {
+	// siblings are the separation plus one more radius apart
+	root, nodes := newPlaceableCompleteTree()
+	bitreevis.PerformLayoutWithSpacing(root, bitreevis.Spacing{NodeRadius: 20, Sibling: 20, Cousin: 20, Level: 20})
+	require.Equal(t, float32(80), nodes[4].X-nodes[3].X)
+	require.Equal(t, float32(80), nodes[5].X-nodes[4].X)
+
+	// cousins are kept further apart, siblings are not
+	root, nodes = newPlaceableCompleteTree()
+	bitreevis.PerformLayoutWithSpacing(root, bitreevis.Spacing{NodeRadius: 20, Sibling: 20, Cousin: 60, Level: 20})
+	require.Equal(t, float32(80), nodes[4].X-nodes[3].X)
+	require.GreaterOrEqual(t, nodes[5].X-nodes[4].X, float32(100))
+
+	// compact layout places nodes exactly the separation apart
+	root, nodes = newPlaceableCompleteTree()
+	bitreevis.PerformLayoutWithSpacing(root, bitreevis.Spacing{NodeRadius: 20, Sibling: 20, Cousin: 20, Level: 20, Compact: true})
+	require.Equal(t, float32(60), nodes[4].X-nodes[3].X)
+	require.Equal(t, float32(60), nodes[5].X-nodes[4].X)
+	lone := bitreevis.NewPlaceableNode("lone")
+	lone.Left = bitreevis.NewPlaceableNode("child")
+	bitreevis.PerformLayoutWithSpacing(lone, bitreevis.Spacing{NodeRadius: 20, Sibling: 20, Level: 20, Compact: true})
+	require.Equal(t, float32(-20), lone.Left.X)
+
+	// per-depth gaps and fractional spacing
+	root, nodes = newPlaceableCompleteTree()
+	bitreevis.PerformLayoutWithSpacing(root, bitreevis.Spacing{
+		NodeRadius: 5,
+		Sibling:    2.5,
+		Cousin:     2.5,
+		LevelAt:    bitreevis.LevelSeparations(1.5, 10),
+	})
+	require.Equal(t, float32(11.5), nodes[1].Y)
+	require.Equal(t, float32(31.5), nodes[6].Y)
+	require.Equal(t, float32(17.5), nodes[4].X-nodes[3].X)
+
+	require.Nil(t, bitreevis.PerformLayoutWithSpacing(nil, bitreevis.Spacing{}))
+	require.Equal(t, float32(0), bitreevis.LevelSeparations()(3))
+}
+
+func TestLayoutWithOption_Spacing(t *testing.T) {
+	opt := (&bitreevis.RenderOption{CousinSeparation: 60, LevelSeparationAt: bitreevis.LevelSeparations(0, 40)}).WithDefaults()
+	root, nodes := newPlaceableCompleteTree()
+	bitreevis.LayoutWithOption(root, opt)
+	require.Equal(t, float32(40), nodes[1].Y)
+	require.Equal(t, float32(120), nodes[3].Y)
+	require.Equal(t, float32(80), nodes[4].X-nodes[3].X)
+	require.GreaterOrEqual(t, nodes[5].X-nodes[4].X, float32(100))
+
+	content := renderForTest(t, newCompleteTreeForTest(3), &bitreevis.RenderOption{CompactLayout: true})
+	requireWellFormed(t, content)
+	plainW, _ := svgSize(t, renderForTest(t, newCompleteTreeForTest(3), nil))
+	w, _ := svgSize(t, content)
+	require.Less(t, w, plainW)
+
+	err := (&bitreevis.RenderOption{CousinSeparation: -1}).Validate()
+	require.True(t, errors.Is(err, bitreevis.ErrInvalidOption))
+
+	// fractional spacing of the option
+	opt = (&bitreevis.RenderOption{NodeRadius: 5, SiblingSeparation: 2.5, LevelSeparation: 1.5}).WithDefaults()
+	root, nodes = newPlaceableCompleteTree()
+	bitreevis.LayoutWithOption(root, opt)
+	require.Equal(t, float32(11.5), nodes[1].Y)
+	require.Equal(t, float32(17.5), nodes[4].X-nodes[3].X)
+	// adjacent columns are nodes[3] and nodes[1] in order, nodes[3] and nodes[4] in a heap
+	for algorithm, next := range map[bitreevis.LayoutAlgorithm]int{bitreevis.LayoutInOrder: 1, bitreevis.LayoutHeap: 4} {
+		opt.Layout = algorithm
+		root, nodes = newPlaceableCompleteTree()
+		bitreevis.LayoutWithOption(root, opt)
+		require.Equal(t, float32(11.5), nodes[1].Y, algorithm)
+		require.Equal(t, float32(12.5), nodes[next].X-nodes[3].X, algorithm)
+	}
+	err = (&bitreevis.RenderOption{LevelSeparation: float32(math.NaN())}).Validate()
+	require.True(t, errors.Is(err, bitreevis.ErrInvalidOption))
+}
+
+func TestRenderOption_TidyOnlySpacing(t *testing.T) {
+	var optErr *bitreevis.OptionError
+	for _, algorithm := range []bitreevis.LayoutAlgorithm{bitreevis.LayoutInOrder, bitreevis.LayoutHeap,
+		bitreevis.LayoutRadial, bitreevis.LayoutHTree} {
+		for field, opt := range map[string]*bitreevis.RenderOption{
+			"CousinSeparation":  {Layout: algorithm, CousinSeparation: 30},
+			"LevelSeparationAt": {Layout: algorithm, LevelSeparationAt: bitreevis.LevelSeparations(10)},
+			"CompactLayout":     {Layout: algorithm, CompactLayout: true},
+		} {
+			require.True(t, errors.As(opt.Validate(), &optErr), field)
+			require.Equal(t, field, optErr.Field)
+
+			// a custom layouter decides itself
+			opt.Layouter = bitreevis.TidyLayouter{}
+			require.Nil(t, opt.Validate(), field)
+		}
+	}
+	require.Nil(t, (&bitreevis.RenderOption{CousinSeparation: 30, CompactLayout: true}).Validate())
+}
@@ -0,0 +1,253 @@
+package bitreevis
+
+import "math"
+
+// Layouter calculates the coordinates of nodes.
+//
+// Layout fills in X and Y of every node in the tree rooted at root, with the spacing specified by opt,
+// and returns the root. The root should be placed at (0, 0). If root is nil, Layout returns nil.
+type Layouter interface {
+	Layout(root *PlaceableNode, opt *RenderOption) *PlaceableNode
+}
+
+// TidyLayouter is the Layouter of LayoutTidy.
+type TidyLayouter struct{}
+
+func (TidyLayouter) Layout(root *PlaceableNode, opt *RenderOption) *PlaceableNode {
+	return PerformLayoutWithSpacing(root, opt.spacing())
+}
+
+// InOrderLayouter is the Layouter of LayoutInOrder.
+type InOrderLayouter struct{}
+
+func (InOrderLayouter) Layout(root *PlaceableNode, opt *RenderOption) *PlaceableNode {
+	diameter := float32(opt.NodeRadius * 2)
+	return layoutInOrder(root, opt.SiblingSeparation+diameter, diameter+opt.LevelSeparation)
+}
+
+// MaxSlotLayoutWidth is the widest drawing, in pixels, made by HeapLayouter and HTreeLayouter. Their drawings
+// double in size with every level or every two levels whatever the number of nodes, so that a chain of 40 nodes
+// would be thousands of billions of pixels wide. Trees whose drawings would be wider or taller are laid out
+// by TidyLayouter instead. The size is measured with columns at least a pixel wide.
+const MaxSlotLayoutWidth = 1 << 20
+
+// HeapLayouter is the Layouter of LayoutHeap.
+//
+// The width of the drawing doubles with every level, so it suits heaps and other complete trees.
+// Trees whose drawings would be wider than MaxSlotLayoutWidth are laid out by TidyLayouter instead.
+type HeapLayouter struct{}
+
+func (HeapLayouter) Layout(root *PlaceableNode, opt *RenderOption) *PlaceableNode {
+	if root == nil {
+		return nil
+	}
+	maxDepth := CalHeight(root) - 1
+	columnWidth := float64(opt.SiblingSeparation) + float64(opt.NodeRadius*2)
+	if math.Ldexp(math.Max(columnWidth, 1), maxDepth) > MaxSlotLayoutWidth {
+		return TidyLayouter{}.Layout(root, opt)
+	}
+	levelHeight := float64(opt.NodeRadius*2) + float64(opt.LevelSeparation)
+	// the slots of the deepest level are a column apart, the root is in the middle of them
+	layoutBySlot(root, func(node, _ *PlaceableNode, depth int, slot float64) {
+		x := (slot+0.5)*math.Ldexp(1, maxDepth-depth) - math.Ldexp(1, maxDepth-1)
+		node.X = float32(x * columnWidth)
+		node.Y = float32(float64(depth) * levelHeight)
+	})
+	return root
+}
+
+// RadialLayouter is the Layouter of LayoutRadial.
+//
+// Every level is on a circle around the root. Every subtree is given a wedge of the circles in proportion
+// to its number of leaves, the left subtree before the right subtree going clockwise from the bottom, and every
+// node is in the middle of its wedge. The circles are far enough apart for the nodes of every level
+// to be a column apart.
+type RadialLayouter struct{}
+
+func (RadialLayouter) Layout(root *PlaceableNode, opt *RenderOption) *PlaceableNode {
+	if root == nil {
+		return nil
+	}
+	columnWidth := float64(opt.SiblingSeparation) + float64(opt.NodeRadius*2)
+
+	// the nodes in pre-order with the index of their parents, so that parents come before children
+	type radialItem struct {
+		node   *PlaceableNode
+		parent int
+		depth  int
+		leaves float64
+		// start and width are the start angle and the angle of the wedge, next is the start of the wedge
+		// of the next child
+		start, width, next float64
+	}
+	items := make([]radialItem, 0, 16)
+	stack := []radialItem{{node: root, parent: -1}}
+	for len(stack) > 0 {
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		items = append(items, item)
+		i := len(items) - 1
+		if item.node.Right != nil {
+			stack = append(stack, radialItem{node: item.node.Right, parent: i, depth: item.depth + 1})
+		}
+		if item.node.Left != nil {
+			stack = append(stack, radialItem{node: item.node.Left, parent: i, depth: item.depth + 1})
+		}
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i].node.IsLeaf() {
+			items[i].leaves = 1
+		}
+		if p := items[i].parent; p >= 0 {
+			items[p].leaves += items[i].leaves
+		}
+	}
+
+	ringGap := float64(opt.NodeRadius*2) + float64(opt.LevelSeparation)
+	items[0].width = 2 * math.Pi
+	for i := 1; i < len(items); i++ {
+		item := &items[i]
+		parent := &items[item.parent]
+		item.width = 2 * math.Pi * item.leaves / items[0].leaves
+		item.start = parent.next
+		item.next = item.start
+		parent.next += item.width
+		// neighbours on the circle are at least the narrower wedge apart
+		ringGap = math.Max(ringGap, columnWidth/(float64(item.depth)*item.width))
+	}
+
+	root.X, root.Y = 0, 0
+	for _, item := range items[1:] {
+		angle := item.start + item.width/2
+		r := float64(item.depth) * ringGap
+		// angles start from the bottom and go clockwise
+		item.node.X = float32(-r * math.Sin(angle))
+		item.node.Y = float32(r * math.Cos(angle))
+	}
+	return root
+}
+
+// HTreeLayouter is the Layouter of LayoutHTree.
+//
+// Edges are horizontal and vertical by turns, starting from horizontal edges at the root, and their length
+// halves every two levels. Nodes of the deepest level are a column apart, a column is the larger separation
+// of SiblingSeparation and LevelSeparation plus the diameter of nodes.
+// Trees whose drawings would be wider or taller than MaxSlotLayoutWidth are laid out by TidyLayouter instead.
+type HTreeLayouter struct{}
+
+func (HTreeLayouter) Layout(root *PlaceableNode, opt *RenderOption) *PlaceableNode {
+	if root == nil {
+		return nil
+	}
+	maxDepth := CalHeight(root) - 1
+	columnWidth := math.Max(float64(opt.SiblingSeparation), float64(opt.LevelSeparation)) + float64(opt.NodeRadius*2)
+	// the edges of the root are the longest, and the edges of every two levels are half as long,
+	// so the drawing spans less than four edges of the root in both directions
+	if 4*math.Ldexp(math.Max(columnWidth, 1), (maxDepth-1)/2) > MaxSlotLayoutWidth {
+		return TidyLayouter{}.Layout(root, opt)
+	}
+	layoutBySlot(root, func(node, parent *PlaceableNode, depth int, slot float64) {
+		if parent == nil {
+			node.X, node.Y = 0, 0
+			return
+		}
+		// the depth of the parent decides the direction and the length of the edge
+		length := columnWidth * math.Ldexp(1, (maxDepth-depth)/2)
+		if math.Mod(slot, 2) == 0 {
+			length = -length
+		}
+		node.X, node.Y = parent.X, parent.Y
+		if (depth-1)%2 == 0 {
+			node.X += float32(length)
+		} else {
+			node.Y += float32(length)
+		}
+	})
+	return root
+}
+
+// layoutBySlot calls place with every node of the tree rooted at root in pre-order, with its parent, its depth
+// and its slot in the level of a complete tree, so that the parent is placed before its children.
+// The slot of the root is 0, the slots of the children of a node of slot s are 2s and 2s+1.
+//
+// It uses an explicit stack instead of recursion, so that degenerate trees with millions of levels can be handled.
+func layoutBySlot(root *PlaceableNode, place func(node, parent *PlaceableNode, depth int, slot float64)) {
+	type slotItem struct {
+		node, parent *PlaceableNode
+		depth        int
+		slot         float64
+	}
+	stack := []slotItem{{node: root}}
+	for len(stack) > 0 {
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if item.node == nil {
+			continue
+		}
+		place(item.node, item.parent, item.depth, item.slot)
+		stack = append(stack,
+			slotItem{node: item.node.Right, parent: item.node, depth: item.depth + 1, slot: item.slot*2 + 1},
+			slotItem{node: item.node.Left, parent: item.node, depth: item.depth + 1, slot: item.slot * 2},
+		)
+	}
+}
+
+// layouters are the Layouters of LayoutAlgorithms.
+var layouters = [...]Layouter{
+	LayoutTidy:    TidyLayouter{},
+	LayoutInOrder: InOrderLayouter{},
+	LayoutHeap:    HeapLayouter{},
+	LayoutRadial:  RadialLayouter{},
+	LayoutHTree:   HTreeLayouter{},
+}
+
+// Layouter returns the Layouter of a. It returns nil if a is unknown.
+func (a LayoutAlgorithm) Layouter() Layouter {
+	if a < 0 || int(a) >= len(layouters) {
+		return nil
+	}
+	return layouters[a]
+}
+
+// spacing returns the Spacing specified by opt.
+func (opt *RenderOption) spacing() Spacing {
+	cousin := opt.CousinSeparation
+	if cousin == 0 {
+		cousin = opt.SiblingSeparation
+	}
+	return Spacing{
+		NodeRadius: float32(opt.NodeRadius),
+		Sibling:    opt.SiblingSeparation,
+		Cousin:     cousin,
+		Level:      opt.LevelSeparation,
+		LevelAt:    opt.LevelSeparationAt,
+		Compact:    opt.CompactLayout,
+	}
+}
+
+// layouter returns the Layouter chosen by opt.
+func (opt *RenderOption) layouter() Layouter {
+	if opt.Layouter != nil {
+		return opt.Layouter
+	}
+	return opt.Layout.Layouter()
+}
+
+// LayoutWithOption calculates the coordinates of every node in the tree rooted at root
+// with the algorithm and the spacing specified by opt. The result can be rendered by any Renderer.
+// If opt.Mirror is set, the drawing is reflected horizontally around the root.
+func LayoutWithOption(root *PlaceableNode, opt *RenderOption) *PlaceableNode {
+	root = opt.layouter().Layout(root, opt)
+	if opt.Mirror && root != nil {
+		mirrorLayout(root)
+	}
+	return root
+}
+
+// mirrorLayout reflects the laid out tree rooted at root horizontally around the root.
+func mirrorLayout(root *PlaceableNode) {
+	x := root.X
+	for _, node := range root.CollectNodes() {
+		node.X = x - (node.X - x)
+	}
+}
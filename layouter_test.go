@@ -0,0 +1,162 @@
+package bitreevis_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+func TestLayouters(t *testing.T) {
+	for _, algorithm := range []bitreevis.LayoutAlgorithm{bitreevis.LayoutTidy, bitreevis.LayoutInOrder,
+		bitreevis.LayoutHeap, bitreevis.LayoutRadial, bitreevis.LayoutHTree} {
+		opt := (&bitreevis.RenderOption{Layout: algorithm}).WithDefaults()
+		pRoot := bitreevis.LayoutWithOption(bitreevis.NewPlaceableTreeFromBiNode(newCompleteTreeForTest(5)), opt)
+		require.Equal(t, float32(0), pRoot.X, algorithm)
+		require.Equal(t, float32(0), pRoot.Y, algorithm)
+
+		// nodes do not overlap
+		nodes := pRoot.CollectNodes()
+		for i, a := range nodes {
+			for _, b := range nodes[i+1:] {
+				d := math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+				require.GreaterOrEqual(t, d, float64(2*opt.NodeRadius), "%v: %s and %s", algorithm, a.Field, b.Field)
+			}
+		}
+
+		require.Nil(t, algorithm.Layouter().Layout(nil, opt))
+		content := renderForTest(t, newCompleteTreeForTest(5), &bitreevis.RenderOption{Layout: algorithm})
+		requireWellFormed(t, content)
+	}
+	require.Nil(t, (bitreevis.LayoutHTree + 1).Layouter())
+}
+
+func TestHeapLayouter(t *testing.T) {
+	opt := (&bitreevis.RenderOption{}).WithDefaults()
+	column := opt.SiblingSeparation + float32(2*opt.NodeRadius)
+	pRoot := bitreevis.HeapLayouter{}.Layout(bitreevis.NewPlaceableTreeFromBiNode(newCompleteTreeForTest(3)), opt)
+	// the deepest level is a column apart
+	nodes := pRoot.CollectNodes()
+	for i, x := range []float32{-1.5, -1, -0.5, 0, 0.5, 1, 1.5} {
+		require.Equal(t, x*column, nodes[i].X, nodes[i].Field)
+	}
+
+	// a missing child keeps its slot empty
+	pRoot = bitreevis.HeapLayouter{}.Layout(bitreevis.NewPlaceableTreeFromBiNode(&myNode{Value: 1, Right: &myNode{Value: 2}}), opt)
+	require.Equal(t, column/2, pRoot.Right.X)
+}
+
+func TestHTreeLayouter(t *testing.T) {
+	opt := (&bitreevis.RenderOption{}).WithDefaults()
+	pRoot := bitreevis.HTreeLayouter{}.Layout(bitreevis.NewPlaceableTreeFromBiNode(newCompleteTreeForTest(3)), opt)
+	// edges from the root are horizontal, edges from its children are vertical
+	require.Equal(t, float32(0), pRoot.Left.Y)
+	require.Less(t, pRoot.Left.X, float32(0))
+	require.Equal(t, pRoot.Left.X, pRoot.Left.Left.X)
+	require.Less(t, pRoot.Left.Left.Y, float32(0))
+	require.Greater(t, pRoot.Left.Right.Y, float32(0))
+}
+
+// countingLayouter is a custom Layouter which counts its calls.
+type countingLayouter struct {
+	calls int
+}
+
+func (l *countingLayouter) Layout(root *bitreevis.PlaceableNode, opt *bitreevis.RenderOption) *bitreevis.PlaceableNode {
+	l.calls++
+	return bitreevis.InOrderLayouter{}.Layout(root, opt)
+}
+
+func TestLayoutWithOption_CustomLayouter(t *testing.T) {
+	l := &countingLayouter{}
+	content := renderForTest(t, newBstForTest(), &bitreevis.RenderOption{Layout: bitreevis.LayoutRadial, Layouter: l})
+	requireWellFormed(t, content)
+	require.Equal(t, 1, l.calls)
+}
+
+func TestRadialLayouter_Wedges(t *testing.T) {
+	opt := (&bitreevis.RenderOption{}).WithDefaults()
+	// the left subtree has 3 leaves, the right subtree has 1 leaf
+	root := &myNode{Value: 1,
+		Left:  &myNode{Value: 2, Left: &myNode{Value: 3}, Right: &myNode{Value: 4, Left: &myNode{Value: 5}, Right: &myNode{Value: 6}}},
+		Right: &myNode{Value: 7},
+	}
+	pRoot := bitreevis.RadialLayouter{}.Layout(bitreevis.NewPlaceableTreeFromBiNode(root), opt)
+	angle := func(node *bitreevis.PlaceableNode) float64 {
+		// angles start from the bottom and go clockwise
+		return math.Mod(math.Atan2(-float64(node.X), float64(node.Y))+2*math.Pi, 2*math.Pi)
+	}
+	radius := func(node *bitreevis.PlaceableNode) float64 {
+		return math.Hypot(float64(node.X), float64(node.Y))
+	}
+	require.InDelta(t, 3*math.Pi/4, angle(pRoot.Left), 1e-5)
+	require.InDelta(t, 7*math.Pi/4, angle(pRoot.Right), 1e-5)
+	require.InDelta(t, radius(pRoot.Left), radius(pRoot.Right), 1e-3)
+	require.InDelta(t, 2*radius(pRoot.Left), radius(pRoot.Left.Left), 1e-3)
+	// every leaf has a quarter of the circle
+	require.InDelta(t, math.Pi/4, angle(pRoot.Left.Left), 1e-5)
+	require.InDelta(t, 3*math.Pi/4, angle(pRoot.Left.Right.Left), 1e-5)
+	require.InDelta(t, 5*math.Pi/4, angle(pRoot.Left.Right.Right), 1e-5)
+}
+
+func TestSvgRenderer_ArcEdgesAndRingGuides(t *testing.T) {
+	content := renderForTest(t, newCompleteTreeForTest(4), &bitreevis.RenderOption{
+		Layout:        bitreevis.LayoutRadial,
+		EdgeRouting:   bitreevis.EdgeRoutingArc,
+		EdgeWithArrow: true,
+		RingGuides:    true,
+		TightBounds:   true,
+	})
+	requireWellFormed(t, content)
+	// edges from the root are straight, the other 12 edges follow the circles
+	require.Equal(t, 12, strings.Count(content, " A "))
+	require.Equal(t, 3, strings.Count(content, "stroke-dasharray:4 4"))
+
+	content = renderForTest(t, newCompleteTreeForTest(4), &bitreevis.RenderOption{
+		Layout:     bitreevis.LayoutRadial,
+		RingGuides: true,
+		CSSClasses: true,
+	})
+	requireWellFormed(t, content)
+	require.Equal(t, 3, strings.Count(content, `class="ring-guide"`))
+}
+
+func TestLayouters_DeepChain(t *testing.T) {
+	for _, length := range []int{49, 1100} {
+		for _, algorithm := range []bitreevis.LayoutAlgorithm{bitreevis.LayoutTidy, bitreevis.LayoutInOrder,
+			bitreevis.LayoutHeap, bitreevis.LayoutRadial, bitreevis.LayoutHTree} {
+			opt := (&bitreevis.RenderOption{Layout: algorithm}).WithDefaults()
+			pRoot := bitreevis.LayoutWithOption(bitreevis.NewPlaceableTreeFromBiNode(newChainForTest(length, true)), opt)
+			for _, node := range pRoot.CollectNodes() {
+				require.False(t, math.IsNaN(float64(node.X)) || math.IsInf(float64(node.X), 0), "%v: %s", algorithm, node.Field)
+				require.False(t, math.IsNaN(float64(node.Y)) || math.IsInf(float64(node.Y), 0), "%v: %s", algorithm, node.Field)
+			}
+			content := renderForTest(t, newChainForTest(length, true), &bitreevis.RenderOption{Layout: algorithm})
+			require.NotContains(t, content, "NaN", algorithm)
+			require.NotContains(t, content, "Inf", algorithm)
+		}
+	}
+
+	// the drawings of short chains fit, the drawings of longer ones would be too wide and are laid out as LayoutTidy
+	opt := (&bitreevis.RenderOption{}).WithDefaults()
+	for _, layouter := range []bitreevis.Layouter{bitreevis.HeapLayouter{}, bitreevis.HTreeLayouter{}} {
+		for _, length := range []int{10, 40, 1100} {
+			pRoot := layouter.Layout(bitreevis.NewPlaceableTreeFromBiNode(newChainForTest(length, true)), opt)
+			tidy := bitreevis.TidyLayouter{}.Layout(bitreevis.NewPlaceableTreeFromBiNode(newChainForTest(length, true)), opt)
+			nodes, tidyNodes := pRoot.CollectNodes(), tidy.CollectNodes()
+			var minX, maxX, minY, maxY float32
+			sameAsTidy := true
+			for i, node := range nodes {
+				minX, maxX = float32(math.Min(float64(minX), float64(node.X))), float32(math.Max(float64(maxX), float64(node.X)))
+				minY, maxY = float32(math.Min(float64(minY), float64(node.Y))), float32(math.Max(float64(maxY), float64(node.Y)))
+				sameAsTidy = sameAsTidy && node.X == tidyNodes[i].X && node.Y == tidyNodes[i].Y
+			}
+			require.LessOrEqual(t, maxX-minX, float32(bitreevis.MaxSlotLayoutWidth), "%T %d", layouter, length)
+			require.LessOrEqual(t, maxY-minY, float32(bitreevis.MaxSlotLayoutWidth), "%T %d", layouter, length)
+			require.Equal(t, length > 10, sameAsTidy, "%T %d", layouter, length)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package bitreevis
+
+import (
+	"math"
+	"strconv"
+)
+
+// LevelStats contains the statistics of a level of the visible tree.
+type LevelStats struct {
+	// Level is the depth of the level, the visible root is at level 0.
+	Level int
+	// Nodes is the number of nodes in the level, placeholders of collapsed subtrees are not counted.
+	Nodes int
+	// FillRatio is Nodes divided by the number of nodes in the level of a complete binary tree.
+	FillRatio float64
+}
+
+// DefaultLevelLabel returns the label of a level, for example "level 2".
+func DefaultLevelLabel(stats LevelStats) string {
+	return "level " + strconv.Itoa(stats.Level)
+}
+
+// levelStatsText returns the statistics of a level in text, for example "3 nodes, 75% full".
+func levelStatsText(stats LevelStats) string {
+	return countNoun(stats.Nodes, "node") + ", " + strconv.FormatFloat(stats.FillRatio*100, 'g', 3, 64) + "% full"
+}
+
+// levelGuide is a guide line of a level with its label.
+type levelGuide struct {
+	stats LevelStats
+	// y is the vertical position of the level in the coordinates of layout.
+	y     float32
+	lines []string
+}
+
+const (
+	// levelLabelTextSize is the font size of level labels.
+	levelLabelTextSize = 12
+	// levelGuideOpacity is the opacity of guide lines, which are faint so that they do not distract from edges.
+	levelGuideOpacity = 0.2
+)
+
+// collectLevelGuides returns the guides of every level of the tree rooted at root, from top to bottom.
+func collectLevelGuides(root *PlaceableNode, opt *RenderOption) []levelGuide {
+	var guides []levelGuide
+	walkLevels(root, func(node *PlaceableNode, depth int) {
+		if len(guides) <= depth {
+			guides = append(guides, levelGuide{stats: LevelStats{Level: depth}})
+		}
+		g := &guides[depth]
+		g.y = node.Y
+		if !node.Collapsed {
+			g.stats.Nodes++
+		}
+	})
+
+	label := opt.LevelLabel
+	if label == nil {
+		label = DefaultLevelLabel
+	}
+	for i := range guides {
+		g := &guides[i]
+		g.stats.FillRatio = float64(g.stats.Nodes) / math.Ldexp(1, g.stats.Level)
+		g.lines = []string{label(g.stats)}
+		if opt.LevelStats {
+			g.lines = append(g.lines, levelStatsText(g.stats))
+		}
+	}
+	return guides
+}
+
+// levelMarginWidth returns the width of the left margin holding the labels of guides.
+func levelMarginWidth(guides []levelGuide, opt *RenderOption) float64 {
+	if len(guides) == 0 {
+		return 0
+	}
+	measurer := opt.textMeasurer()
+	font := Font{Family: opt.NodeFieldFontFamily, Size: levelLabelTextSize}
+	width := 0.0
+	for _, g := range guides {
+		for _, line := range g.lines {
+			width = math.Max(width, measurer.MeasureString(line, font))
+		}
+	}
+	return math.Ceil(width + float64(opt.horizontalPadding()))
+}
+
+// addLevelGuides renders a faint horizontal line at every level across the tree area, with the label
+// of the level in the left margin. shiftY is the vertical shift of the tree.
+func (sr *SvgRenderer) addLevelGuides(guides []levelGuide, shiftY float32, opt *RenderOption) {
+	lineHeight := levelLabelTextSize * defaultLineHeight
+	x1, x2 := sr.layout.treeLeft, sr.layout.width-float64(opt.horizontalPadding())
+	var lineAttrs []svgAttribute
+	if opt.CSSClasses {
+		lineAttrs = []svgAttribute{{key: "class", value: classLevelGuide}}
+	} else {
+		lineAttrs = []svgAttribute{styleAttr([]svgStyleAttribute{
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: "1"},
+			{key: "stroke-dasharray", value: "4 4"},
+			{key: "opacity", value: strconv.FormatFloat(levelGuideOpacity, 'f', 3, 64)},
+		})}
+	}
+	for _, g := range guides {
+		y := float64(shiftY + g.y)
+		sr.constructLine(x1, y, x2, y, lineAttrs)
+		// the lines of the label are centred on the guide line
+		firstDy := levelLabelTextSize/3 - float64(len(g.lines)-1)*lineHeight/2
+		x := float32(opt.horizontalPadding())
+		if opt.CSSClasses {
+			sr.constructText(x, float32(y), g.lines, firstDy, lineHeight, "", []svgAttribute{{key: "class", value: classLevelLabel}})
+			continue
+		}
+		styles := []svgStyleAttribute{{key: "font-size", value: strconv.Itoa(levelLabelTextSize)}}
+		if opt.NodeFieldFontFamily != "" {
+			styles = append(styles, svgStyleAttribute{key: "font-family", value: opt.NodeFieldFontFamily})
+		}
+		styles = append(styles, svgStyleAttribute{key: "fill", value: opt.EdgeLineColor})
+		sr.constructText(x, float32(y), g.lines, firstDy, lineHeight, "", []svgAttribute{styleAttr(styles)})
+	}
+}
+
+// collectRingRadii returns the radii of the circles around root through every level of the tree rooted at root,
+// from the top level to the bottom level. The radius of a level is the largest distance of its nodes from root.
+func collectRingRadii(root *PlaceableNode) []float64 {
+	var radii []float64
+	walkLevels(root, func(node *PlaceableNode, depth int) {
+		if len(radii) <= depth {
+			radii = append(radii, 0)
+		}
+		d := math.Hypot(float64(node.X-root.X), float64(node.Y-root.Y))
+		radii[depth] = math.Max(radii[depth], d)
+	})
+	// the root is not on a circle
+	rings := make([]float64, 0, len(radii))
+	for _, r := range radii {
+		if r > 0 {
+			rings = append(rings, r)
+		}
+	}
+	return rings
+}
+
+// extendRingBounds extends b to contain the circles of radii around root.
+func extendRingBounds(b *bounds, root *PlaceableNode, radii []float64) {
+	outer := 0.0
+	for _, r := range radii {
+		outer = math.Max(outer, r)
+	}
+	// half of the stroke of guides
+	b.extendAround(float64(root.X), float64(root.Y), outer+0.5)
+}
+
+// addRingGuides renders a faint circle of each of radii around root.
+func (sr *SvgRenderer) addRingGuides(root *PlaceableNode, radii []float64, opt *RenderOption) {
+	var attrs []svgAttribute
+	if opt.CSSClasses {
+		attrs = []svgAttribute{{key: "class", value: classRingGuide}}
+	} else {
+		attrs = []svgAttribute{styleAttr([]svgStyleAttribute{
+			{key: "fill", value: "none"},
+			{key: "stroke", value: opt.EdgeLineColor},
+			{key: "stroke-width", value: "1"},
+			{key: "stroke-dasharray", value: "4 4"},
+			{key: "opacity", value: strconv.FormatFloat(levelGuideOpacity, 'f', 3, 64)},
+		})}
+	}
+	for _, r := range radii {
+		sr.constructCircle(root.X, root.Y, float32(r), attrs)
+	}
+}
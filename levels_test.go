@@ -0,0 +1,69 @@
+package bitreevis_test
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+func TestSvgRenderer_LevelGuides(t *testing.T) {
+	plainW, _ := svgSize(t, renderForTest(t, newBstForTest(), nil))
+	content := renderForTest(t, newBstForTest(), &bitreevis.RenderOption{LevelGuides: true, LevelStats: true})
+	requireWellFormed(t, content)
+	w, _ := svgSize(t, content)
+	require.Greater(t, w, plainW)
+	require.Equal(t, 4, strings.Count(content, "stroke-dasharray:4 4"))
+	require.Contains(t, content, ">level 0</tspan>")
+	require.Contains(t, content, ">1 node, 100% full</tspan>")
+	require.Contains(t, content, ">level 3</tspan>")
+	require.Contains(t, content, ">1 node, 12.5% full</tspan>")
+
+	content = renderForTest(t, newBstForTest(), &bitreevis.RenderOption{
+		LevelGuides: true,
+		LevelLabel: func(stats bitreevis.LevelStats) string {
+			return "depth " + strconv.Itoa(stats.Level) + " / " + strconv.Itoa(stats.Nodes)
+		},
+		CSSClasses: true,
+	})
+	require.Contains(t, content, ">depth 2 / 4</tspan>")
+	require.Contains(t, content, `class="level-guide"`)
+	require.NotContains(t, content, "% full")
+}
+
+// renderWithoutDepthForTest renders root like renderForTest after clearing PlaceableNode.Depth, as a tree built
+// by hand would have it.
+func renderWithoutDepthForTest(t *testing.T, root bitreevis.BiNode, opt *bitreevis.RenderOption) string {
+	opt = opt.WithDefaults()
+	pRoot := bitreevis.NewPlaceableTreeFromBiNodeWithOption(root, opt)
+	for _, node := range pRoot.CollectNodes() {
+		node.Depth = 0
+	}
+	pRoot = bitreevis.LayoutWithOption(pRoot, opt)
+	result := bitreevis.NewSvgRenderer().Render(pRoot, opt)
+	require.Nil(t, result.Error())
+	content, err := io.ReadAll(result.GetContent())
+	require.Nil(t, err)
+	return string(content)
+}
+
+func TestSvgRenderer_DepthOfHandBuiltTree(t *testing.T) {
+	for _, newOpt := range []func() *bitreevis.RenderOption{
+		func() *bitreevis.RenderOption { return &bitreevis.RenderOption{LevelGuides: true, LevelStats: true} },
+		func() *bitreevis.RenderOption {
+			return &bitreevis.RenderOption{NodePalette: bitreevis.DepthPalette("#000001", "#000002")}
+		},
+		func() *bitreevis.RenderOption {
+			return &bitreevis.RenderOption{NodePalette: bitreevis.SubtreePalette(1, "#00000a", "#00000b")}
+		},
+		func() *bitreevis.RenderOption {
+			return &bitreevis.RenderOption{Layout: bitreevis.LayoutRadial, RingGuides: true}
+		},
+	} {
+		require.Equal(t, renderForTest(t, newBstForTest(), newOpt()), renderWithoutDepthForTest(t, newBstForTest(), newOpt()))
+	}
+}
@@ -0,0 +1,109 @@
+package bitreevis_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+// mirrorShape returns a new tree which is the mirror image of the tree rooted at root.
+func mirrorShape(root *bitreevis.PlaceableNode) *bitreevis.PlaceableNode {
+	if root == nil {
+		return nil
+	}
+	mirror := bitreevis.NewPlaceableNode(root.Field)
+	mirror.Left, mirror.Right = mirrorShape(root.Right), mirrorShape(root.Left)
+	return mirror
+}
+
+// requireMirrored checks that the laid out tree mirror is the reflection of the laid out tree rooted at root.
+func requireMirrored(t *testing.T, root, mirror *bitreevis.PlaceableNode) {
+	if root == nil {
+		require.Nil(t, mirror)
+		return
+	}
+	require.Equal(t, root.X, -mirror.X, root.Field)
+	require.Equal(t, root.Y, mirror.Y, root.Field)
+	requireMirrored(t, root.Left, mirror.Right)
+	requireMirrored(t, root.Right, mirror.Left)
+}
+
+func TestPerformLayout_MirrorImage(t *testing.T) {
+	spacings := []bitreevis.Spacing{
+		{NodeRadius: 10, Sibling: 10, Cousin: 10, Level: 10},
+		{NodeRadius: 10, Sibling: 10, Cousin: 30, Level: 10},
+		{NodeRadius: 10, Sibling: 10, Cousin: 10, Level: 10, Compact: true},
+	}
+	rnd := rand.New(rand.NewSource(2))
+	for i := 0; i < 200; i++ {
+		root := bitreevis.NewPlaceableNode("500")
+		for n := rnd.Intn(60); n > 0; n-- {
+			bstInsert(root, rnd.Intn(1000))
+		}
+		mirror := mirrorShape(root)
+		sp := spacings[i%len(spacings)]
+		bitreevis.PerformLayoutWithSpacing(root, sp)
+		bitreevis.PerformLayoutWithSpacing(mirror, sp)
+		requireMirrored(t, root, mirror)
+	}
+}
+
+func TestLayoutWithOption_Mirror(t *testing.T) {
+	for _, layout := range []bitreevis.LayoutAlgorithm{bitreevis.LayoutTidy, bitreevis.LayoutInOrder, bitreevis.LayoutRadial} {
+		opt := (&bitreevis.RenderOption{Layout: layout}).WithDefaults()
+		root := bitreevis.LayoutWithOption(bitreevis.NewPlaceableTreeFromBiNode(newBstForTest()), opt)
+		opt.Mirror = true
+		mirror := bitreevis.LayoutWithOption(bitreevis.NewPlaceableTreeFromBiNode(newBstForTest()), opt)
+		// the tree is reflected, not its mirror image laid out, so the children stay where they are in the tree
+		nodes, mirrorNodes := root.CollectNodes(), mirror.CollectNodes()
+		require.Len(t, mirrorNodes, len(nodes))
+		for i := range nodes {
+			require.Equal(t, nodes[i].X, -mirrorNodes[i].X, nodes[i].Field)
+			require.Equal(t, nodes[i].Y, mirrorNodes[i].Y, nodes[i].Field)
+		}
+	}
+	require.Nil(t, bitreevis.LayoutWithOption(nil, &bitreevis.RenderOption{Mirror: true}))
+
+	content := renderForTest(t, newBstForTest(), &bitreevis.RenderOption{Mirror: true})
+	requireWellFormed(t, content)
+}
+
+// shapeOf returns a string which is the same for two subtrees if and only if they are isomorphic.
+func shapeOf(root *bitreevis.PlaceableNode, shapes map[*bitreevis.PlaceableNode]string) string {
+	if root == nil {
+		return "."
+	}
+	shape := "(" + shapeOf(root.Left, shapes) + shapeOf(root.Right, shapes) + ")"
+	shapes[root] = shape
+	return shape
+}
+
+func TestPerformLayout_IsomorphicSubtrees(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	for i := 0; i < 50; i++ {
+		root := bitreevis.NewPlaceableNode("500")
+		for n := rnd.Intn(100); n > 0; n-- {
+			bstInsert(root, rnd.Intn(1000))
+		}
+		bitreevis.PerformLayout(root, 10, 10, 10)
+
+		shapes := make(map[*bitreevis.PlaceableNode]string)
+		shapeOf(root, shapes)
+		// the positions of the nodes relative to the root of the first subtree of every shape
+		drawings := make(map[string][]float32)
+		for node, shape := range shapes {
+			var drawing []float32
+			for _, n := range node.CollectNodes() {
+				drawing = append(drawing, n.X-node.X, n.Y-node.Y)
+			}
+			if expected, ok := drawings[shape]; ok {
+				require.Equal(t, expected, drawing, shape)
+			} else {
+				drawings[shape] = drawing
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package bitreevis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Default sizes for the graphic, used when the corresponding fields of RenderOption are zero.
+const (
+	DefaultNodeRadius        = 20
+	DefaultSiblingSeparation = 20
+	DefaultLevelSeparation   = 20
+	DefaultHorizontalPadding = 10
+	DefaultVerticalPadding   = 10
+
+	DefaultEmptyTreeText = "empty tree"
+)
+
+// NoPadding is the value of RenderOption.HorizontalPadding and RenderOption.VerticalPadding for no padding,
+// since zero means the default padding.
+const NoPadding = -1
+
+// DimTransparent is the value of RenderOption.DimOpacity for hiding the nodes and edges which are not highlighted,
+// since zero means the default opacity.
+const DimTransparent = -1
+
+// ErrInvalidOption is matched by every error returned from RenderOption.Validate, it can be checked with errors.Is.
+var ErrInvalidOption = errors.New("bitreevis: invalid render option")
+
+// OptionError describes an invalid field of RenderOption.
+type OptionError struct {
+	// Field is the name of the invalid field.
+	Field string
+	// Value is the invalid value of the field.
+	Value interface{}
+	// Reason describes why the value is invalid.
+	Reason string
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("bitreevis: invalid render option %s=%v: %s", e.Field, e.Value, e.Reason)
+}
+
+// Is reports whether target is ErrInvalidOption.
+func (e *OptionError) Is(target error) bool {
+	return target == ErrInvalidOption
+}
+
+// Validate checks the values of the option and returns an *OptionError for the first invalid field.
+//
+// Zero values are always valid, they are replaced by defaults as documented in WithDefaults.
+// A nil option is valid.
+func (opt *RenderOption) Validate() error {
+	if opt == nil {
+		return nil
+	}
+	for _, p := range []struct {
+		field string
+		value int
+	}{{"HorizontalPadding", opt.HorizontalPadding}, {"VerticalPadding", opt.VerticalPadding}} {
+		if p.value < 0 && p.value != NoPadding {
+			return &OptionError{Field: p.field, Value: p.value, Reason: "must not be negative other than NoPadding"}
+		}
+	}
+	nonNegatives := []struct {
+		field string
+		value int
+	}{
+		{"CanvasWidth", opt.CanvasWidth},
+		{"CanvasHeight", opt.CanvasHeight},
+		{"MaxCanvasWidth", opt.MaxCanvasWidth},
+		{"MaxCanvasHeight", opt.MaxCanvasHeight},
+		{"NodeRadius", opt.NodeRadius},
+		{"NodeStrokeWidth", opt.NodeStrokeWidth},
+		{"NodeFieldTextSize", opt.NodeFieldTextSize},
+		{"NodeFieldMaxWidth", opt.NodeFieldMaxWidth},
+		{"NodeFieldMaxLines", opt.NodeFieldMaxLines},
+		{"EdgeLineWidth", opt.EdgeLineWidth},
+		{"EdgeArrowSize", opt.EdgeArrowSize},
+		{"EdgeLabelTextSize", opt.EdgeLabelTextSize},
+		{"HighlightStrokeWidth", opt.HighlightStrokeWidth},
+		{"MaxDepth", opt.MaxDepth},
+		{"MaxNodes", opt.MaxNodes},
+	}
+	for _, n := range nonNegatives {
+		if n.value < 0 {
+			return &OptionError{Field: n.field, Value: n.value, Reason: "must not be negative"}
+		}
+	}
+	for _, s := range []struct {
+		field string
+		value float32
+	}{
+		{"SiblingSeparation", opt.SiblingSeparation},
+		{"CousinSeparation", opt.CousinSeparation},
+		{"LevelSeparation", opt.LevelSeparation},
+	} {
+		if !(s.value >= 0) || math.IsInf(float64(s.value), 0) {
+			return &OptionError{Field: s.field, Value: s.value, Reason: "must be a non-negative finite number"}
+		}
+	}
+	if opt.NodeFieldOverflow != TextOverflowEllipsis && opt.NodeFieldOverflow != TextOverflowWrap {
+		return &OptionError{Field: "NodeFieldOverflow", Value: opt.NodeFieldOverflow, Reason: "unknown text overflow"}
+	}
+	if opt.Layout < LayoutTidy || opt.Layout > LayoutHTree {
+		return &OptionError{Field: "Layout", Value: opt.Layout, Reason: "unknown layout algorithm"}
+	}
+	if opt.Layouter == nil && opt.Layout != LayoutTidy {
+		// the other built-in layouts place nodes in columns, rings or slots, which have no contours
+		switch {
+		case opt.CousinSeparation != 0:
+			return &OptionError{Field: "CousinSeparation", Value: opt.CousinSeparation, Reason: "is supported by LayoutTidy only"}
+		case opt.LevelSeparationAt != nil:
+			return &OptionError{Field: "LevelSeparationAt", Value: "func", Reason: "is supported by LayoutTidy only"}
+		case opt.CompactLayout:
+			return &OptionError{Field: "CompactLayout", Value: opt.CompactLayout, Reason: "is supported by LayoutTidy only"}
+		}
+	}
+	if opt.EdgeRouting < EdgeRoutingStraight || opt.EdgeRouting > EdgeRoutingArc {
+		return &OptionError{Field: "EdgeRouting", Value: opt.EdgeRouting, Reason: "unknown edge routing"}
+	}
+	if opt.DimOpacity != DimTransparent && !(opt.DimOpacity >= 0 && opt.DimOpacity <= 1) {
+		return &OptionError{Field: "DimOpacity", Value: opt.DimOpacity, Reason: "must be in range [0, 1] or DimTransparent"}
+	}
+	if p, ok := opt.NodePalette.(*GradientPalette); ok && p != nil {
+		if p.Value == nil {
+			return &OptionError{Field: "NodePalette", Value: p, Reason: "gradient palette has no Value function"}
+		}
+		for _, color := range []string{p.From, p.To} {
+			if _, err := parseHexColor(color); err != nil {
+				return &OptionError{Field: "NodePalette", Value: color, Reason: "gradient palette colors must be hex colors"}
+			}
+		}
+	}
+
+	return nil
+}
+
+// WithDefaults returns a copy of the option whose zero-value fields are replaced by defaults.
+//
+// Sizes default to DefaultNodeRadius, DefaultSiblingSeparation, DefaultLevelSeparation, DefaultHorizontalPadding,
+// DefaultVerticalPadding and the other Default* constants. Colors default to DefaultBackgroundColor,
+// DefaultNodeColor, DefaultNodeFieldTextColor, DefaultEdgeColor, DefaultHighlightColor and DefaultPlaceholderColor,
+// NodeLeafColor defaults to NodeColor. NodeStrokeColor stays empty, which means nodes have no stroke.
+// Zero MaxDepth and MaxNodes mean no limit. NoPadding and DimTransparent are kept, so applying defaults
+// twice is harmless.
+// If opt is nil, an option made of defaults is returned.
+func (opt *RenderOption) WithDefaults() *RenderOption {
+	o := RenderOption{}
+	if opt != nil {
+		o = *opt
+	}
+
+	setDefaultInt(&o.HorizontalPadding, DefaultHorizontalPadding)
+	setDefaultInt(&o.VerticalPadding, DefaultVerticalPadding)
+	setDefaultFloat32(&o.SiblingSeparation, DefaultSiblingSeparation)
+	setDefaultFloat32(&o.LevelSeparation, DefaultLevelSeparation)
+	setDefaultInt(&o.NodeRadius, DefaultNodeRadius)
+	setDefaultInt(&o.NodeStrokeWidth, DefaultNodeStrokeWidth)
+	setDefaultInt(&o.NodeFieldTextSize, DefaultNodeFieldTextSize)
+	setDefaultInt(&o.EdgeLineWidth, DefaultEdgeLineWidth)
+	setDefaultInt(&o.EdgeArrowSize, DefaultEdgeArrowSize)
+	setDefaultInt(&o.EdgeLabelTextSize, DefaultEdgeLabelTextSize)
+	setDefaultInt(&o.HighlightStrokeWidth, DefaultHighlightStrokeWidth)
+	if o.DimOpacity == 0 {
+		o.DimOpacity = DefaultDimOpacity
+	}
+
+	setDefaultString(&o.BackgroundColor, DefaultBackgroundColor)
+	setDefaultString(&o.NodeColor, DefaultNodeColor)
+	setDefaultString(&o.NodeLeafColor, o.NodeColor)
+	setDefaultString(&o.NodeFieldTextColor, DefaultNodeFieldTextColor)
+	setDefaultString(&o.EdgeLineColor, DefaultEdgeColor)
+	setDefaultString(&o.HighlightColor, DefaultHighlightColor)
+	setDefaultString(&o.PlaceholderColor, DefaultPlaceholderColor)
+	setDefaultString(&o.EmptyTreeText, DefaultEmptyTreeText)
+
+	return &o
+}
+
+func setDefaultInt(v *int, def int) {
+	if *v == 0 {
+		*v = def
+	}
+}
+
+func setDefaultFloat32(v *float32, def float32) {
+	if *v == 0 {
+		*v = def
+	}
+}
+
+func setDefaultString(v *string, def string) {
+	if *v == "" {
+		*v = def
+	}
+}
@@ -0,0 +1,147 @@
+package bitreevis_test
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+func TestRenderOption_Validate(t *testing.T) {
+	require.Nil(t, (*bitreevis.RenderOption)(nil).Validate())
+	require.Nil(t, (&bitreevis.RenderOption{}).Validate())
+
+	err := (&bitreevis.RenderOption{NodeRadius: -1}).Validate()
+	require.True(t, errors.Is(err, bitreevis.ErrInvalidOption))
+	var optErr *bitreevis.OptionError
+	require.True(t, errors.As(err, &optErr))
+	require.Equal(t, "NodeRadius", optErr.Field)
+	require.Equal(t, -1, optErr.Value)
+
+	err = (&bitreevis.RenderOption{DimOpacity: 1.5}).Validate()
+	require.True(t, errors.As(err, &optErr))
+	require.Equal(t, "DimOpacity", optErr.Field)
+	err = (&bitreevis.RenderOption{DimOpacity: math.NaN()}).Validate()
+	require.True(t, errors.As(err, &optErr))
+	require.Equal(t, "DimOpacity", optErr.Field)
+
+	require.Nil(t, (&bitreevis.RenderOption{DimOpacity: bitreevis.DimTransparent}).Validate())
+	err = (&bitreevis.RenderOption{DimOpacity: -0.5}).Validate()
+	require.True(t, errors.As(err, &optErr))
+	require.Equal(t, "DimOpacity", optErr.Field)
+
+	require.Nil(t, (&bitreevis.RenderOption{HorizontalPadding: bitreevis.NoPadding}).Validate())
+	err = (&bitreevis.RenderOption{VerticalPadding: -2}).Validate()
+	require.True(t, errors.As(err, &optErr))
+	require.Equal(t, "VerticalPadding", optErr.Field)
+}
+
+// visAsSvgForTest returns the graphic of the tree rooted at root drawn by VisAsSvg.
+func visAsSvgForTest(t *testing.T, root bitreevis.BiNode, opt *bitreevis.RenderOption) string {
+	filename := filepath.Join(t.TempDir(), "tree.svg")
+	require.Nil(t, bitreevis.VisAsSvg(root, filename, opt))
+	content, err := os.ReadFile(filename)
+	require.Nil(t, err)
+	return string(content)
+}
+
+// renderedSize returns the size of the graphic of the tree rooted at root drawn by VisAsSvg.
+func renderedSize(t *testing.T, root bitreevis.BiNode, opt *bitreevis.RenderOption) (int, int) {
+	m := regexp.MustCompile(`<svg width="(\d+)" height="(\d+)"`).FindStringSubmatch(visAsSvgForTest(t, root, opt))
+	require.NotNil(t, m)
+	w, _ := strconv.Atoi(m[1])
+	h, _ := strconv.Atoi(m[2])
+	return w, h
+}
+
+func TestRenderOption_NoPadding(t *testing.T) {
+	opt := (&bitreevis.RenderOption{HorizontalPadding: bitreevis.NoPadding}).WithDefaults()
+	require.Equal(t, bitreevis.NoPadding, opt.HorizontalPadding)
+	require.Equal(t, bitreevis.DefaultVerticalPadding, opt.VerticalPadding)
+
+	w, h := renderedSize(t, newBstForTest(), nil)
+	noPadW, noPadH := renderedSize(t, newBstForTest(), &bitreevis.RenderOption{
+		HorizontalPadding: bitreevis.NoPadding,
+		VerticalPadding:   bitreevis.NoPadding,
+	})
+	require.Equal(t, w-2*bitreevis.DefaultHorizontalPadding, noPadW)
+	require.Equal(t, h-2*bitreevis.DefaultVerticalPadding, noPadH)
+}
+
+func TestRenderOption_WithDefaults(t *testing.T) {
+	opt := (*bitreevis.RenderOption)(nil).WithDefaults()
+	require.Equal(t, bitreevis.DefaultNodeRadius, opt.NodeRadius)
+	require.Equal(t, bitreevis.DefaultBackgroundColor, opt.BackgroundColor)
+	require.Equal(t, bitreevis.DefaultNodeColor, opt.NodeLeafColor)
+
+	origin := &bitreevis.RenderOption{NodeRadius: 5, NodeColor: "red"}
+	opt = origin.WithDefaults()
+	require.Equal(t, 5, opt.NodeRadius)
+	require.Equal(t, "red", opt.NodeLeafColor)
+	require.Equal(t, "", origin.NodeLeafColor)
+}
+
+func TestVisAsSvg_EmptyTree(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "empty.svg")
+	require.Nil(t, bitreevis.VisAsSvg(nil, filename, nil))
+	content, err := os.ReadFile(filename)
+	require.Nil(t, err)
+	require.Contains(t, string(content), bitreevis.DefaultEmptyTreeText)
+
+	var nilNode *myNode
+	require.Nil(t, bitreevis.VisAsSvg(nilNode, filename, &bitreevis.RenderOption{EmptyTreeText: "nothing"}))
+	content, err = os.ReadFile(filename)
+	require.Nil(t, err)
+	require.Contains(t, string(content), "nothing")
+}
+
+func TestVisAsSvg_InvalidOption(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "invalid.svg")
+	err := bitreevis.VisAsSvg(newBstForTest(), filename, &bitreevis.RenderOption{SiblingSeparation: -3})
+	require.True(t, errors.Is(err, bitreevis.ErrInvalidOption))
+	_, err = os.Stat(filename)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestSvgRenderer_NilRootAndOption(t *testing.T) {
+	var renderer bitreevis.Renderer = bitreevis.NewSvgRenderer()
+	result := renderer.Render(nil, nil)
+	require.Nil(t, result.Error())
+	content, err := io.ReadAll(result.GetContent())
+	require.Nil(t, err)
+	require.Contains(t, string(content), bitreevis.DefaultEmptyTreeText)
+
+	result = bitreevis.NewSvgRenderer().Render(nil, &bitreevis.RenderOption{EdgeArrowSize: -1})
+	require.True(t, errors.Is(result.Error(), bitreevis.ErrInvalidOption))
+	require.NotNil(t, result.Save(filepath.Join(t.TempDir(), "invalid.svg")))
+
+	require.Nil(t, bitreevis.PerformLayout(nil, 1, 1, 1))
+}
+
+func TestRenderOption_DimTransparent(t *testing.T) {
+	opt := (&bitreevis.RenderOption{DimOpacity: bitreevis.DimTransparent}).WithDefaults()
+	require.Equal(t, float64(bitreevis.DimTransparent), opt.DimOpacity)
+
+	content := visAsSvgForTest(t, newBstForTest(), &bitreevis.RenderOption{
+		Highlight:  bitreevis.HighlightSearch("9"),
+		DimOpacity: bitreevis.DimTransparent,
+	})
+	require.Contains(t, content, "opacity:0.000")
+	require.NotContains(t, content, "opacity:0.300")
+	require.NotContains(t, content, "opacity:-")
+
+	content = renderForTest(t, newBstForTest(), &bitreevis.RenderOption{
+		Highlight:  bitreevis.HighlightSearch("9"),
+		DimOpacity: bitreevis.DimTransparent,
+		CSSClasses: true,
+	})
+	require.Contains(t, content, ".dimmed{opacity:0.000}")
+}
@@ -0,0 +1,220 @@
+package bitreevis
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultPaletteColors are the colors used by DepthPalette and SubtreePalette if no color is given.
+// They are the colors of the Okabe-Ito palette, which are distinguishable with color vision deficiency.
+var DefaultPaletteColors = []string{"#e69f00", "#56b4e9", "#009e73", "#f0e442", "#0072b2", "#d55e00", "#cc79a7"}
+
+// A Palette chooses the colors of nodes automatically, it is used by RenderOption.NodePalette.
+//
+// Nodes with a local color specified by PaintableBiNode keep their own color.
+// A palette is not modified by rendering, so that renderings running at the same time can share it.
+type Palette interface {
+	// Prepare is called with the root of the tree before the tree is rendered,
+	// it returns the colors of the nodes for this rendering.
+	Prepare(root *PlaceableNode) NodeColors
+}
+
+// NodeColors are the colors of the nodes of a tree chosen by a Palette for one rendering.
+type NodeColors interface {
+	// NodeColor returns the color of node. An empty string means the global color of the option.
+	NodeColor(node *PlaceableNode) string
+}
+
+// LegendNodeColors are NodeColors described by a gradient legend rendered below the tree.
+type LegendNodeColors interface {
+	NodeColors
+
+	// Legend returns the legend of the colors.
+	Legend() GradientLegend
+}
+
+// nodeColorMap is NodeColors made of the color of every node, nodes not in the map use the global color.
+type nodeColorMap map[*PlaceableNode]string
+
+func (m nodeColorMap) NodeColor(node *PlaceableNode) string {
+	return m[node]
+}
+
+// GradientLegend describes a gradient from color From at value Min to color To at value Max.
+type GradientLegend struct {
+	Title    string
+	From, To string
+	Min, Max float64
+}
+
+// DepthPalette returns a Palette which colors nodes by their depth, cycling through colors.
+// If colors is empty, DefaultPaletteColors is used.
+func DepthPalette(colors ...string) Palette {
+	if len(colors) == 0 {
+		colors = DefaultPaletteColors
+	}
+	return &depthPalette{colors: colors}
+}
+
+type depthPalette struct {
+	colors []string
+}
+
+func (p *depthPalette) Prepare(root *PlaceableNode) NodeColors {
+	colors := make(nodeColorMap)
+	walkLevels(root, func(node *PlaceableNode, depth int) {
+		colors[node] = p.colors[depth%len(p.colors)]
+	})
+	return colors
+}
+
+// SubtreePalette returns a Palette which colors each subtree rooted at the given depth with its own color,
+// cycling through colors from left to right. Nodes above depth use the global color.
+// If colors is empty, DefaultPaletteColors is used.
+func SubtreePalette(depth int, colors ...string) Palette {
+	if len(colors) == 0 {
+		colors = DefaultPaletteColors
+	}
+	return &subtreePalette{depth: depth, colors: colors}
+}
+
+type subtreePalette struct {
+	depth  int
+	colors []string
+}
+
+func (p *subtreePalette) Prepare(root *PlaceableNode) NodeColors {
+	colors := make(nodeColorMap)
+	if root == nil {
+		return colors
+	}
+	// subtree roots are found in level order so that they are numbered from left to right
+	var subtreeRoots []*PlaceableNode
+	for level, depth := []*PlaceableNode{root}, 0; len(level) != 0; depth++ {
+		var next []*PlaceableNode
+		for _, node := range level {
+			if depth == p.depth {
+				subtreeRoots = append(subtreeRoots, node)
+				continue
+			}
+			for _, child := range [2]*PlaceableNode{node.Left, node.Right} {
+				if child != nil {
+					next = append(next, child)
+				}
+			}
+		}
+		level = next
+	}
+
+	for i, subtreeRoot := range subtreeRoots {
+		color := p.colors[i%len(p.colors)]
+		for _, node := range subtreeRoot.CollectNodes() {
+			colors[node] = color
+		}
+	}
+	return colors
+}
+
+// GradientPalette is a Palette which colors nodes by a numeric value, interpolating linearly
+// between From at the smallest value and To at the largest value. From and To must be in the form of
+// "#rgb" or "#rrggbb". Its colors are described by a gradient legend rendered below the tree.
+type GradientPalette struct {
+	// Value returns the numeric value of node.
+	Value func(node BiNode) float64
+	// From and To are the colors of the smallest and the largest value.
+	From, To string
+	// Min and Max specify the range of values. If both are zero, the range of the values in the tree is used.
+	Min, Max float64
+	// Title is the title of the legend.
+	Title string
+}
+
+// NewGradientPalette returns a GradientPalette which colors nodes by value from color from to color to.
+func NewGradientPalette(value func(node BiNode) float64, from, to string) *GradientPalette {
+	return &GradientPalette{Value: value, From: from, To: to}
+}
+
+func (p *GradientPalette) Prepare(root *PlaceableNode) NodeColors {
+	c := &gradientColors{palette: p, min: p.Min, max: p.Max}
+	c.from, _ = parseHexColor(p.From)
+	c.to, _ = parseHexColor(p.To)
+	if p.Min != 0 || p.Max != 0 || root == nil {
+		return c
+	}
+	c.min, c.max = math.Inf(1), math.Inf(-1)
+	for _, node := range root.CollectNodes() {
+		if node.Collapsed || node.Source == nil {
+			continue
+		}
+		v := p.Value(node.Source)
+		c.min = math.Min(c.min, v)
+		c.max = math.Max(c.max, v)
+	}
+	if c.min > c.max {
+		c.min, c.max = 0, 0
+	}
+	return c
+}
+
+// gradientColors are the colors chosen by a GradientPalette for one rendering.
+type gradientColors struct {
+	palette *GradientPalette
+	// min and max are the range of values of this rendering.
+	min, max float64
+	from, to [3]float64
+}
+
+var _ LegendNodeColors = (*gradientColors)(nil)
+
+func (c *gradientColors) NodeColor(node *PlaceableNode) string {
+	if node.Source == nil {
+		return ""
+	}
+	return c.colorAt(c.palette.Value(node.Source))
+}
+
+// colorAt returns the color of value v.
+func (c *gradientColors) colorAt(v float64) string {
+	t := 0.0
+	if c.max > c.min {
+		t = math.Max(0, math.Min(1, (v-c.min)/(c.max-c.min)))
+	}
+	var rgb [3]float64
+	for i := range rgb {
+		rgb[i] = c.from[i] + (c.to[i]-c.from[i])*t
+	}
+	return formatHexColor(rgb)
+}
+
+// Legend returns the legend of the colors with the range of values of this rendering.
+func (c *gradientColors) Legend() GradientLegend {
+	p := c.palette
+	return GradientLegend{Title: p.Title, From: p.From, To: p.To, Min: c.min, Max: c.max}
+}
+
+// parseHexColor parses color in the form of "#rgb" or "#rrggbb" into its red, green and blue components.
+func parseHexColor(color string) ([3]float64, error) {
+	var c [3]float64
+	s := strings.TrimPrefix(color, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 || !strings.HasPrefix(color, "#") {
+		return c, fmt.Errorf("bitreevis: invalid hex color %q", color)
+	}
+	for i := range c {
+		v, err := strconv.ParseUint(s[i*2:i*2+2], 16, 8)
+		if err != nil {
+			return c, fmt.Errorf("bitreevis: invalid hex color %q", color)
+		}
+		c[i] = float64(v)
+	}
+	return c, nil
+}
+
+// formatHexColor formats c in the form of "#rrggbb".
+func formatHexColor(c [3]float64) string {
+	return fmt.Sprintf("#%02x%02x%02x", uint8(math.Round(c[0])), uint8(math.Round(c[1])), uint8(math.Round(c[2])))
+}
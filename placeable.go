@@ -21,6 +21,26 @@ type PlaceableNode struct {
 	Thread bool
 	Field  string
 	Color  string
+
+	// Classes are the user-defined css classes of this node, see StyledBiNode.
+	Classes []string
+
+	// Source is the BiNode this node was built from, nil if the node is not built from a BiNode.
+	Source BiNode
+
+	// Highlighted reports whether this node is emphasised when rendering.
+	Highlighted bool
+	// HighlightedEdge reports whether the edge from the parent to this node is emphasised when rendering.
+	HighlightedEdge bool
+
+	// Depth is the depth of this node in the visible tree, the visible root is at depth 0.
+	Depth int
+	// Collapsed reports whether this node is a placeholder standing for a truncated subtree.
+	Collapsed bool
+	// HiddenNodes is the number of nodes in the truncated subtree if the node is collapsed.
+	HiddenNodes int
+	// HiddenHeight is the height of the truncated subtree if the node is collapsed.
+	HiddenHeight int
 }
 
 func (p *PlaceableNode) IsLeaf() bool {
@@ -43,18 +63,28 @@ func (p *PlaceableNode) GetField() string {
 	return p.Field
 }
 
+// CollectNodes collects all nodes of the tree rooted at p in in-order.
 func (p *PlaceableNode) CollectNodes() []*PlaceableNode {
 	nodes := make([]*PlaceableNode, 0, 16) // pre-allocation
 	return inOrderTraverse(p, nodes)
 }
 
+// inOrderTraverse appends the nodes of the tree rooted at root to nodes in in-order.
+//
+// It uses an explicit stack instead of recursion, so that degenerate trees with millions of levels can be handled.
 func inOrderTraverse(root *PlaceableNode, nodes []*PlaceableNode) []*PlaceableNode {
-	if root == nil {
-		return nodes
+	stack := make([]*PlaceableNode, 0, 16)
+	cur := root
+	for cur != nil || len(stack) > 0 {
+		for cur != nil {
+			stack = append(stack, cur)
+			cur = cur.Left
+		}
+		cur = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		nodes = append(nodes, cur)
+		cur = cur.Right
 	}
-	nodes = inOrderTraverse(root.Left, nodes)
-	nodes = append(nodes, root)
-	nodes = inOrderTraverse(root.Right, nodes)
 
 	return nodes
 }
@@ -66,6 +96,8 @@ type SizeLimitStat struct {
 	MaxY float32
 }
 
+// CollectNodesWithStat collects all nodes of the tree rooted at p in in-order, with the extents of their positions.
+// The extents of an empty tree are all zero.
 func (p *PlaceableNode) CollectNodesWithStat() (nodes []*PlaceableNode, limit *SizeLimitStat) {
 	nodes = make([]*PlaceableNode, 0, 16)
 	limit = &SizeLimitStat{}
@@ -75,17 +107,20 @@ func (p *PlaceableNode) CollectNodesWithStat() (nodes []*PlaceableNode, limit *S
 }
 
 func inOrderTraverseWithStat(root *PlaceableNode, nodes []*PlaceableNode, limit *SizeLimitStat) []*PlaceableNode {
-	if root == nil {
+	start := len(nodes)
+	nodes = inOrderTraverse(root, nodes)
+	if len(nodes) == start {
 		return nodes
 	}
-	limit.MinX = minFloat32(root.X, limit.MinX)
-	limit.MaxX = maxFloat32(root.X, limit.MaxX)
-	limit.MinY = minFloat32(root.Y, limit.MinY)
-	limit.MaxY = maxFloat32(root.Y, limit.MaxY)
-
-	nodes = inOrderTraverseWithStat(root.Left, nodes, limit)
-	nodes = append(nodes, root)
-	nodes = inOrderTraverseWithStat(root.Right, nodes, limit)
+	// the extents start from the first node instead of the origin
+	first := nodes[start]
+	*limit = SizeLimitStat{MinX: first.X, MaxX: first.X, MinY: first.Y, MaxY: first.Y}
+	for _, node := range nodes[start+1:] {
+		limit.MinX = minFloat32(node.X, limit.MinX)
+		limit.MaxX = maxFloat32(node.X, limit.MaxX)
+		limit.MinY = minFloat32(node.Y, limit.MinY)
+		limit.MaxY = maxFloat32(node.Y, limit.MaxY)
+	}
 
 	return nodes
 }
@@ -97,27 +132,69 @@ func NewPlaceableNode(field string) *PlaceableNode {
 
 // NewPlaceableTreeFromBiNode builds a tree made of placeableNode from a tree made of BiNode
 func NewPlaceableTreeFromBiNode(root BiNode) *PlaceableNode {
-	return buildPlaceableTreeRecursive(root)
-}
-
-// buildPlaceableTreeRecursive helps build tree in a recursive manner
-func buildPlaceableTreeRecursive(root BiNode) *PlaceableNode {
-	if BiNodeIsNil(root) {
+	if biNodeIsNil(root) {
 		return nil
 	}
-	pRoot := NewPlaceableNode(root.GetField())
-	if color, ok := isPaintable(root); ok {
-		pRoot.Color = color
+	return buildPlaceableTree(root, 0, 0, nil)
+}
+
+// placeableNodeArena allocates PlaceableNodes from contiguous chunks instead of one heap object each.
+//
+// A chunk is kept alive as long as any node in it is referenced.
+type placeableNodeArena struct {
+	chunk []PlaceableNode
+}
+
+const (
+	minArenaChunkSize = 64
+	maxArenaChunkSize = 64 * 1024
+)
+
+func (a *placeableNodeArena) alloc() *PlaceableNode {
+	if len(a.chunk) == cap(a.chunk) {
+		size := cap(a.chunk) * 2
+		if size < minArenaChunkSize {
+			size = minArenaChunkSize
+		} else if size > maxArenaChunkSize {
+			size = maxArenaChunkSize
+		}
+		a.chunk = make([]PlaceableNode, 0, size)
 	}
+	a.chunk = a.chunk[:len(a.chunk)+1]
+	return &a.chunk[len(a.chunk)-1]
+}
 
-	pRoot.Left = buildPlaceableTreeRecursive(root.GetLeftChild())
-	if pRoot.Left != nil {
-		pRoot.Left.Parent = pRoot
+// newPlaceableNodeFromBiNode returns a new *PlaceableNode carrying the content of node without children.
+func (a *placeableNodeArena) newPlaceableNodeFromBiNode(node BiNode, depth int) *PlaceableNode {
+	pNode := a.alloc()
+	pNode.Field = node.GetField()
+	pNode.Source = node
+	pNode.Depth = depth
+	if color, ok := isPaintable(node); ok {
+		pNode.Color = color
 	}
-	pRoot.Right = buildPlaceableTreeRecursive(root.GetRightChild())
-	if pRoot.Right != nil {
-		pRoot.Right.Parent = pRoot
+	if styled, ok := node.(StyledBiNode); ok {
+		pNode.Classes = styled.GetClasses()
 	}
+	return pNode
+}
 
-	return pRoot
+// walkLevels calls visit with every node of the tree rooted at root and its depth below root, in level order.
+// The depth is counted during the walk, so it is right for trees built by hand whose Depth is not set.
+func walkLevels(root *PlaceableNode, visit func(node *PlaceableNode, depth int)) {
+	if root == nil {
+		return
+	}
+	for level, depth := []*PlaceableNode{root}, 0; len(level) != 0; depth++ {
+		var next []*PlaceableNode
+		for _, node := range level {
+			visit(node, depth)
+			for _, child := range [2]*PlaceableNode{node.Left, node.Right} {
+				if child != nil {
+					next = append(next, child)
+				}
+			}
+		}
+		level = next
+	}
 }
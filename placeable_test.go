@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/ryanreadbooks/bitreevis"
 )
 
@@ -30,3 +32,61 @@ func TestBuildPlaceableTree_Dev(t *testing.T) {
 		fmt.Println("\n---------------")
 	}
 }
+
+// ptrishNode is a value-type node made of a single pointer, which is not nil even if the pointer is nil.
+type ptrishNode struct {
+	p *int
+}
+
+func (ptrishNode) GetField() string                { return "ptrish" }
+func (ptrishNode) GetLeftChild() bitreevis.BiNode  { return nil }
+func (ptrishNode) GetRightChild() bitreevis.BiNode { return nil }
+
+func TestNewPlaceableTreeFromBiNode_ValueNodes(t *testing.T) {
+	require.False(t, bitreevis.BiNodeIsNil(ptrishNode{}))
+	root := &myNode{Value: 1}
+	pRoot := bitreevis.NewPlaceableTreeFromBiNode(&wrapperNode{myNode: root, left: ptrishNode{}})
+	require.NotNil(t, pRoot.Left)
+	require.Equal(t, "ptrish", pRoot.Left.Field)
+
+	var nilNode *myNode
+	require.True(t, bitreevis.BiNodeIsNil(nilNode))
+	require.Nil(t, bitreevis.NewPlaceableTreeFromBiNode(nilNode))
+}
+
+// wrapperNode is a node with the content of myNode and any BiNode as its left child.
+type wrapperNode struct {
+	*myNode
+	left bitreevis.BiNode
+}
+
+func (n *wrapperNode) GetLeftChild() bitreevis.BiNode { return n.left }
+
+// sentinelNode is a node of a tree whose leaves point to a shared sentinel, as in red-black trees.
+type sentinelNode struct {
+	left, right *sentinelNode
+	sentinel    bool
+	value       int
+}
+
+func (n *sentinelNode) GetField() string                { return fmt.Sprint(n.value) }
+func (n *sentinelNode) GetLeftChild() bitreevis.BiNode  { return n.left }
+func (n *sentinelNode) GetRightChild() bitreevis.BiNode { return n.right }
+func (n *sentinelNode) IsNil() bool                     { return n == nil || n.sentinel }
+
+func TestNewPlaceableTreeFromBiNode_NilableNodes(t *testing.T) {
+	sentinel := &sentinelNode{sentinel: true}
+	leaf := &sentinelNode{left: sentinel, right: sentinel, value: 1}
+	root := &sentinelNode{left: leaf, right: sentinel, value: 2}
+	require.True(t, bitreevis.BiNodeIsNil(sentinel))
+	require.True(t, bitreevis.BiNodeIsNil((*sentinelNode)(nil)))
+
+	pRoot := bitreevis.NewPlaceableTreeFromBiNode(root)
+	require.Len(t, pRoot.CollectNodes(), 2)
+	require.Nil(t, pRoot.Right)
+	require.True(t, pRoot.Left.IsLeaf())
+
+	pRoot = bitreevis.NewPlaceableTreeFromBiNodeWithOption(root, &bitreevis.RenderOption{MaxNodes: 1})
+	require.True(t, pRoot.Left.Collapsed)
+	require.Equal(t, 1, pRoot.Left.HiddenNodes)
+}
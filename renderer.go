@@ -17,6 +17,14 @@ const (
 	DefaultEdgeColor     = "black"
 	DefaultEdgeArrowSize = 2
 	DefaultEdgeLineWidth = 2
+
+	DefaultEdgeLabelTextSize = 12
+
+	DefaultHighlightColor       = "#ff5722"
+	DefaultHighlightStrokeWidth = 4
+	DefaultDimOpacity           = 0.3
+
+	DefaultPlaceholderColor = "#d9d9d9"
 )
 
 // RenderResult contains rendered output from renderer.
@@ -40,8 +48,11 @@ type RenderResult interface {
 }
 
 // Render is the interface which defines how to render the binary tree.
+//
+// Render should not panic on a nil tree or an invalid option. A nil tree should be rendered as an empty tree,
+// and an invalid option should be reported by the Error method of RenderResult.
 type Renderer interface {
-	Render(*PlaceableNode, RenderOption) RenderResult
+	Render(*PlaceableNode, *RenderOption) RenderResult
 }
 
 // RenderOption is the options of graphics when rendering.
@@ -50,15 +61,57 @@ type Renderer interface {
 type RenderOption struct {
 	// BackgroundColor specifies the global background color of the whole graphic.
 	BackgroundColor string
-	// BackgroundColor specifies the horizontal padding of the graphic on one size.
+	// HorizontalPadding specifies the horizontal padding of the graphic on one side.
+	// Zero means DefaultHorizontalPadding, use NoPadding for no padding.
 	HorizontalPadding int
-	// BackgroundColor specifies the vertical padding of the graphic on one size.
+	// VerticalPadding specifies the vertical padding of the graphic on one side.
+	// Zero means DefaultVerticalPadding, use NoPadding for no padding.
 	VerticalPadding int
 
+	// CanvasWidth and CanvasHeight specify the size of the graphic, the drawing is scaled to fit in it with
+	// its aspect ratio preserved. If only one of them is specified, the other one follows the aspect ratio.
+	// Zero means the natural size of the drawing.
+	CanvasWidth, CanvasHeight int
+	// MaxCanvasWidth and MaxCanvasHeight specify the maximum size of the graphic, larger drawings are scaled down
+	// with their aspect ratio preserved. Zero means no limit.
+	MaxCanvasWidth, MaxCanvasHeight int
+	// Responsive specifies whether the graphic takes the full width of its container and scales with it.
+	// The size options above are ignored if it is set.
+	Responsive bool
+	// TightBounds specifies whether the canvas fits the true extents of the drawing, including labels, arrows
+	// and strokes. By default the canvas is widened on one side so that the root is at the centre horizontally,
+	// which wastes space for lopsided trees.
+	TightBounds bool
+
 	// SiblingSeparation specifies the minimum gap between two sibling nodes.
-	SiblingSeparation int
+	SiblingSeparation float32
+	// CousinSeparation specifies the minimum gap between two nodes on the same level which have different parents.
+	// Zero means SiblingSeparation.
+	CousinSeparation float32
 	// LevelSeparation specifies the gap between two different levels.
-	LevelSeparation int
+	LevelSeparation float32
+	// LevelSeparationAt returns the gap between the levels at depth and depth+1.
+	// If not nil, it is used in place of LevelSeparation. LevelSeparations makes one from a slice of gaps.
+	LevelSeparationAt func(depth int) float32
+	// CompactLayout specifies whether subtrees nest under the contours of each other more tightly.
+	//
+	// CousinSeparation, LevelSeparationAt and CompactLayout are supported by LayoutTidy only,
+	// Validate rejects them with the other values of Layout. A custom Layouter may use them or not.
+	CompactLayout bool
+	// Layout specifies the algorithm which places nodes, LayoutTidy by default.
+	Layout LayoutAlgorithm
+	// Layouter specifies a custom algorithm which places nodes. If not nil, it is used in place of Layout.
+	Layouter Layouter
+	// Mirror specifies whether to reflect the drawing horizontally, so that left children are drawn on the right.
+	Mirror bool
+	// SortedAxis specifies whether to draw an axis below the tree with the labels of nodes in in-order,
+	// and a dotted projection line from every node down to the axis. It suits LayoutInOrder, with which
+	// every node is right above its label on the axis.
+	SortedAxis bool
+	// ArrayView specifies whether to draw the array of a tree of IndexedBiNode below the tree, such as a heap
+	// made by FromHeapSlice: a row of cells with the labels of nodes at their indices, the indices below the cells,
+	// and a dashed connector from every node down to its cell.
+	ArrayView bool
 
 	// NodeRadius specifies the radius of node.
 	NodeRadius int
@@ -74,6 +127,25 @@ type RenderOption struct {
 	NodeFieldTextSize int
 	// NodeFieldTextColor specifies the color of font inside of node.
 	NodeFieldTextColor string
+	// NodeFieldFontFamily specifies the font family of text inside of node as in css, for example "Arial, sans-serif".
+	NodeFieldFontFamily string
+	// NodeFieldFontWeight specifies the font weight of text inside of node as in css, for example "bold".
+	NodeFieldFontWeight string
+	// NodeFieldFontStyle specifies the font style of text inside of node as in css, for example "italic".
+	NodeFieldFontStyle string
+	// NodeFitText specifies whether to enlarge NodeRadius so that the text of every node fits inside of it.
+	NodeFitText bool
+	// TextMeasurer specifies how to measure text for sizing nodes, centring and truncating text.
+	// If nil, DefaultTextMeasurer is used.
+	TextMeasurer TextMeasurer
+	// NodeFieldMaxWidth specifies the maximum width of each line of text inside of node. Zero means no limit.
+	// Text is split into lines on '\n'.
+	NodeFieldMaxWidth int
+	// NodeFieldOverflow specifies how a line of text wider than NodeFieldMaxWidth is handled.
+	NodeFieldOverflow TextOverflow
+	// NodeFieldMaxLines specifies the maximum number of lines of text inside of node. Zero means no limit.
+	// If any part of the text is dropped, the full text is kept as a tooltip.
+	NodeFieldMaxLines int
 
 	// EdgeLineWidth specifies the width of edges which connects nodes.
 	EdgeLineWidth int
@@ -83,6 +155,143 @@ type RenderOption struct {
 	EdgeWithArrow bool
 	// EdgeArrowSize specifies the arrow size of edge
 	EdgeArrowSize int
+	// EdgeRouting specifies the shape of edges, straight lines by default.
+	EdgeRouting EdgeRouting
+	// EdgeLabelTextSize specifies the font size of edge labels given by EdgeLabeledBiNode.
+	EdgeLabelTextSize int
+
+	// Highlight specifies the nodes to be emphasised, nodes and edges not highlighted are dimmed.
+	// If nil, nothing is highlighted.
+	Highlight *Highlighter
+	// HighlightColor specifies the stroke color of highlighted nodes and the color of highlighted edges.
+	HighlightColor string
+	// HighlightStrokeWidth specifies the stroke-width of highlighted nodes and edges.
+	HighlightStrokeWidth int
+	// DimOpacity specifies the opacity of nodes and edges which are not highlighted.
+	// Zero means DefaultDimOpacity, use DimTransparent to hide them.
+	DimOpacity float64
+
+	// MaxDepth specifies the maximum depth of visible nodes, the visible root is at depth 0.
+	// Deeper subtrees are collapsed into placeholders. Zero means no limit.
+	MaxDepth int
+	// MaxNodes specifies the maximum number of visible nodes, nodes are kept in level order.
+	// Remaining subtrees are collapsed into placeholders. Zero means no limit.
+	MaxNodes int
+	// Focus specifies the node around which the visible window is placed instead of the root.
+	// It is found with ==, so Focus has no effect on trees whose nodes cannot be compared, such as structs holding slices.
+	Focus BiNode
+	// PlaceholderColor specifies the color of placeholders standing for collapsed subtrees.
+	PlaceholderColor string
+
+	// NodeColorMap translates the colors specified locally by PaintableBiNode, for example
+	// "red" and "black" of red-black trees, into the colors actually rendered. It is set by themes.
+	NodeColorMap map[string]string
+	// NodePalette chooses the color of each node which has no local color, in place of NodeColor and NodeLeafColor.
+	// If nil, NodeColor and NodeLeafColor are used.
+	NodePalette Palette
+
+	// EmptyTreeText specifies the text shown in the graphic when the tree is empty.
+	EmptyTreeText string
+
+	// CSSClasses specifies whether elements are styled by semantic css classes defined in a single style element,
+	// instead of inline styles. The classes are background, node, leaf, placeholder, label, edge, edge-left,
+	// edge-right, edge-label, arrow, title, subtitle, caption, legend, level-guide, level-label, ring-guide, axis,
+	// projection, axis-label, array-cell, array-index, array-connector, highlighted, dimmed and the classes
+	// of StyledBiNode.
+	// Colors specified for single nodes, by PaintableBiNode or NodePalette, are still inlined.
+	CSSClasses bool
+	// CSSDarkTheme specifies the theme used when the viewer prefers dark color scheme, if CSSClasses is set.
+	// If nil, the graphic looks the same in both color schemes.
+	CSSDarkTheme *Theme
+	// CSSExtra is appended to the style element if CSSClasses is set, for example to style the classes of StyledBiNode.
+	CSSExtra string
+
+	// ElementIDs specifies whether to give deterministic ids to the svg elements of nodes, labels and edges.
+	// Ids are derived from the key of IdentifiableBiNode or the traversal path from the root, for example
+	// the group of the right child of the left child of the root is "n-LR", its label is "l-LR" and
+	// the edge from its parent is "e-LR". The group of the root is "n". Paths longer than 64 steps are shortened
+	// with a hash, and a key used by more than one node is suffixed by "-2", "-3" and so on, so ids are unique.
+	ElementIDs bool
+	// ElementIDPrefix is prepended to every id, so that several graphics can be embedded in one document.
+	ElementIDPrefix string
+
+	// DocumentTitle specifies the title of the graphic, which is rendered as the title element of the document.
+	DocumentTitle string
+	// DocumentDescription specifies the description of the graphic, which is rendered as the desc element of the document.
+	DocumentDescription string
+	// Title specifies the title shown above the tree.
+	Title string
+	// Subtitle specifies the subtitle shown below the title.
+	Subtitle string
+	// Caption specifies the caption shown below the tree, it is wrapped to the width of the graphic.
+	Caption string
+	// Legend specifies whether to show a legend below the tree, explaining the colors of PaintableBiNode,
+	// highlighted nodes and placeholders of collapsed subtrees.
+	Legend bool
+	// LegendLabels maps the colors of PaintableBiNode to their labels in the legend, for example "red" to "red node".
+	// Colors without labels are labelled by themselves.
+	LegendLabels map[string]string
+
+	// LevelGuides specifies whether to draw a faint horizontal guide line at every level of the tree,
+	// with the label of the level in a left margin.
+	LevelGuides bool
+	// LevelLabel returns the label of a level. If nil, DefaultLevelLabel is used.
+	LevelLabel func(stats LevelStats) string
+	// LevelStats specifies whether to add the node count and the fill ratio of every level below its label.
+	LevelStats bool
+	// RingGuides specifies whether to draw a faint circle around the root through every level of the tree,
+	// which suits LayoutRadial.
+	RingGuides bool
+
+	// Accessible specifies whether to add ARIA roles and labels for screen readers. If DocumentTitle or
+	// DocumentDescription is empty, a title and a description of the tree, with its node count, height and
+	// nodes in order, are generated.
+	Accessible bool
+}
+
+func (opt *RenderOption) highlightColor() string {
+	if opt.HighlightColor != "" {
+		return opt.HighlightColor
+	}
+	return DefaultHighlightColor
+}
+
+func (opt *RenderOption) highlightStrokeWidth() int {
+	if opt.HighlightStrokeWidth != 0 {
+		return opt.HighlightStrokeWidth
+	}
+	return DefaultHighlightStrokeWidth
+}
+
+// horizontalPadding returns the horizontal padding in pixels, NoPadding is zero.
+func (opt *RenderOption) horizontalPadding() int {
+	return paddingSize(opt.HorizontalPadding, DefaultHorizontalPadding)
+}
+
+// verticalPadding returns the vertical padding in pixels, NoPadding is zero.
+func (opt *RenderOption) verticalPadding() int {
+	return paddingSize(opt.VerticalPadding, DefaultVerticalPadding)
+}
+
+func paddingSize(padding, def int) int {
+	switch {
+	case padding == 0:
+		return def
+	case padding < 0:
+		return 0
+	}
+	return padding
+}
+
+// dimOpacity returns the opacity of nodes and edges which are not highlighted, DimTransparent is zero.
+func (opt *RenderOption) dimOpacity() float64 {
+	switch {
+	case opt.DimOpacity == 0:
+		return DefaultDimOpacity
+	case opt.DimOpacity < 0:
+		return 0
+	}
+	return opt.DimOpacity
 }
 
 // measureEdgeStartEnd is a helper function for calculating the start and end coordinate of an edge
@@ -91,8 +300,13 @@ type RenderOption struct {
 // Specifically, (x1, y1) is the parent node, (x2, y2) is the child node.
 // radius is the size of node; offsetStart specifies how far the edge start coordinate will go forward;
 // offsetEnd specifies how far the edge end coordinate will go backward;
+//
+// If the two nodes are at the same position, the edge degenerates into the point (x1, y1).
 func measureEdgeStartEnd(x1, y1, x2, y2, radius float64, offsetStart, offsetEnd float64) (edgeStartX, edgeStartY, edgeEndX, edgeEndY float64) {
 	distance := calDistanceBetweenPoints(x1, y1, x2, y2)
+	if distance == 0 {
+		return x1, y1, x1, y1
+	}
 	edgeLength := distance - 2*radius
 	// calculate the start coordinate and end coordinate of edge
 	edgeDirectionXRaw := x2 - x1
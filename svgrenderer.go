@@ -7,6 +7,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	svg "github.com/ajstarks/svgo"
 )
@@ -21,13 +22,17 @@ type SvgRenderResult struct {
 }
 
 // GetContent returns the inner buffer of the rendered result
-// The actual type of the returned io.Reader is strings.Reader.
+// The actual type of the returned io.Reader is strings.Reader. It is empty if an error occurred during rendering.
 func (r *SvgRenderResult) GetContent() io.Reader {
 	return r.content
 }
 
 // Save save the svg graphic into the given file.
+// If an error occurred during rendering, Save returns the error without creating the file.
 func (r *SvgRenderResult) Save(filename string) error {
+	if r.e != nil {
+		return r.e
+	}
 	f, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -45,10 +50,34 @@ func (r *SvgRenderResult) Error() error {
 type SvgRenderer struct {
 	Canvas svg.SVG
 	buf    *strings.Builder
+
+	// edgeAttrs caches the attributes of edges in each state during rendering.
+	edgeAttrs [edgeStateCount][edgeSideCount][]svgAttribute
+	// nodeColors are the colors chosen by the palette for the tree being rendered, nil if there is no palette.
+	nodeColors NodeColors
+	// legend is the gradient legend of the palette being rendered, nil if there is none.
+	legend *GradientLegend
+	// elementKeys are the keys of element ids of nodes being rendered, nil if ids are not rendered.
+	elementKeys map[*PlaceableNode]string
+	// docTitle and docDesc are the title and the description of the document being rendered.
+	docTitle, docDesc string
+	// legendEntries are the entries of the legend explaining node styles, nil if there is no legend.
+	legendEntries []legendEntry
+	// layout is the arrangement of the canvas being rendered.
+	layout canvasLayout
+	// levelGuides are the guides of levels, nil if they are not rendered.
+	levelGuides []levelGuide
+	// root is the root of the tree being rendered, which is the centre of arc edges and ring guides.
+	root *PlaceableNode
 }
 
 const (
-	selfDefinedArrowName = "self-defined-arrow-marker"
+	selfDefinedArrowName          = "self-defined-arrow-marker"
+	selfDefinedHighlightArrowName = "self-defined-highlight-arrow-marker"
+	paletteGradientName           = "self-defined-palette-gradient"
+
+	// estimatedBytesPerNode is the estimated size of the svg elements of a node with its edges.
+	estimatedBytesPerNode = 512
 )
 
 // NewSvgRenderer returns a new SvgRenderer.
@@ -61,18 +90,90 @@ func NewSvgRenderer() *SvgRenderer {
 	return r
 }
 
+var _ Renderer = (*SvgRenderer)(nil)
+
 // Render performs rendering process for specified binary tree.
+//
+// If option is nil, the default option is used. Zero-value fields of option are replaced by defaults.
+// If option is invalid, the returned RenderResult reports the error. If root is nil, an empty tree is rendered.
 func (sr *SvgRenderer) Render(root *PlaceableNode, option *RenderOption) RenderResult {
+	if err := option.Validate(); err != nil {
+		return &SvgRenderResult{content: strings.NewReader(""), e: err}
+	}
+	option = option.WithDefaults()
+	if root == nil {
+		return sr.renderEmpty(option)
+	}
+
+	if option.Highlight != nil {
+		option.Highlight.Apply(root)
+	}
+	sr.edgeAttrs = [edgeStateCount][edgeSideCount][]svgAttribute{}
+	sr.root = root
+	sr.legend = nil
+	sr.elementKeys = nil
+	if option.ElementIDs {
+		sr.elementKeys = buildElementKeys(root)
+	}
+	sr.nodeColors = nil
+	if option.NodePalette != nil {
+		sr.nodeColors = option.NodePalette.Prepare(root)
+		if lc, ok := sr.nodeColors.(LegendNodeColors); ok {
+			legend := lc.Legend()
+			sr.legend = &legend
+		}
+	}
 
 	// init svg renderer
 	nodes, stats := root.CollectNodesWithStat()
-	w, _ := sr.initRenderer(stats, option)
+	sr.docTitle, sr.docDesc = documentMetadata(nodes, option)
+	sr.legendEntries = nil
+	if option.Legend {
+		sr.legendEntries = collectLegendEntries(nodes, option)
+	}
+	sr.levelGuides = nil
+	if option.LevelGuides {
+		sr.levelGuides = collectLevelGuides(root, option)
+	}
+	sr.buf.Grow(len(nodes) * estimatedBytesPerNode)
+	var extents bounds
+	if option.TightBounds {
+		extents = tightBounds(root, nodes, option)
+	} else {
+		// the root is at the center of the tree area horizontally
+		extents = centredBounds(stats, float64(option.NodeRadius))
+	}
+	if option.SortedAxis {
+		extendAxisBounds(&extents, nodes, stats, option.TightBounds, option)
+	}
+	var array *arrayView
+	if option.ArrayView {
+		array = collectArrayView(root, nodes, stats, option)
+		if array != nil {
+			extendArrayBounds(&extents, array)
+		}
+	}
+	var rings []float64
+	if option.RingGuides {
+		rings = collectRingRadii(root)
+		extendRingBounds(&extents, root, rings)
+	}
+	shiftX, shiftY := sr.initRenderer(extents, option)
 
 	// we should do global shift here to place the element in the absolute positions
-	// shiftX := float32(math.Abs(float64(stats.MinX))) + float32(option.NodeRadius) + float32(option.HorizontalPadding)
-	shiftX := w / 2 // we simply put the root at center horizontally
-	shiftY := float32(option.NodeRadius) + float32(option.VerticalPadding)
+	if sr.levelGuides != nil {
+		sr.addLevelGuides(sr.levelGuides, shiftY, option)
+	}
 	sr.Canvas.Group(fmt.Sprintf(`transform="translate(%.3f,%.3f)"`, shiftX, shiftY))
+	if option.SortedAxis {
+		sr.addSortedAxis(nodes, stats, option)
+	}
+	if rings != nil {
+		sr.addRingGuides(root, rings, option)
+	}
+	if array != nil {
+		sr.addArrayView(array, option)
+	}
 
 	// render nodes and edges
 	for _, node := range nodes {
@@ -83,6 +184,7 @@ func (sr *SvgRenderer) Render(root *PlaceableNode, option *RenderOption) RenderR
 	}
 
 	sr.Canvas.Gend()
+	sr.addFrame(option)
 	sr.Canvas.End()
 
 	// organize RenderResult instance
@@ -94,22 +196,54 @@ func (sr *SvgRenderer) Render(root *PlaceableNode, option *RenderOption) RenderR
 	return rr
 }
 
-func (sr *SvgRenderer) initRenderer(stats *SizeLimitStat, opt *RenderOption) (float32, float32) {
-	// we use boxWidth to ensure root node is at the center of graphic
-	// because root is always at (0,0) in relative coordinate
-	boxWidth := math.Max(math.Abs(float64(stats.MinX)), math.Abs(float64(stats.MaxX))) * 2
-	width := boxWidth + float64(opt.NodeRadius)*2 + float64(opt.HorizontalPadding)*2
-	height := math.Abs(float64(stats.MinY)-float64(stats.MaxY)) + float64(opt.NodeRadius)*2 + float64(opt.VerticalPadding)*2
+// renderEmpty renders a graphic of one node size with option.EmptyTreeText in it.
+func (sr *SvgRenderer) renderEmpty(option *RenderOption) RenderResult {
+	sr.root = nil
+	sr.legend = nil
+	sr.elementKeys = nil
+	sr.docTitle, sr.docDesc = documentMetadata(nil, option)
+	sr.legendEntries = nil
+	sr.levelGuides = nil
+	shiftX, shiftY := sr.initRenderer(centredBounds(&SizeLimitStat{}, float64(option.NodeRadius)), option)
+	sr.Canvas.Group(fmt.Sprintf(`transform="translate(%.3f,%.3f)"`, shiftX, shiftY))
+	sr.addText(0, 0, option.EmptyTreeText, "", option, false)
+	sr.Canvas.Gend()
+	sr.addFrame(option)
+	sr.Canvas.End()
+
+	return &SvgRenderResult{content: strings.NewReader(sr.buf.String())}
+}
 
-	sr.Canvas.Start(int(width), int(height))
+// initRenderer starts the document and computes the size of the canvas for a drawing of extents, with space
+// reserved around the tree for the title, the subtitle, the legend and the caption. The arrangement is kept
+// in sr.layout. It returns the shift which places the drawing in the tree area.
+func (sr *SvgRenderer) initRenderer(extents bounds, opt *RenderOption) (float32, float32) {
+	treeWidth := math.Ceil(extents.width()) + float64(opt.horizontalPadding())*2
+	treeHeight := math.Ceil(extents.height()) + float64(opt.verticalPadding())*2
+	sr.layout = arrangeCanvas(treeWidth, treeHeight, levelMarginWidth(sr.levelGuides, opt), sr.legendEntries, sr.legend, opt)
+	width, height := sr.layout.width, sr.layout.height
+
+	sr.startCanvas(int(width), int(height), opt)
+	sr.addDocumentMetadata(sr.docTitle, sr.docDesc, opt)
 
 	if opt.EdgeWithArrow {
 		sr.defineArrow(opt)
 	}
+	if sr.legend != nil {
+		sr.defineLegendGradient(sr.legend)
+	}
 
-	sr.setGlobalBackgroundColor(int(width), int(height), opt.BackgroundColor)
+	if opt.CSSClasses {
+		sr.addStyleSheet(opt)
+		sr.addClassedBackground(int(width), int(height))
+	} else {
+		sr.setGlobalBackgroundColor(int(width), int(height), opt.BackgroundColor)
+	}
 
-	return float32(width), float32(height)
+	// the drawing is centred horizontally in the tree area, which may be widened for the texts around the tree
+	shiftX := sr.layout.treeLeft + (width-sr.layout.treeLeft-extents.width())/2 - extents.minX
+	shiftY := sr.layout.treeTop + float64(opt.verticalPadding()) - extents.minY
+	return float32(shiftX), float32(shiftY)
 }
 
 func (sr *SvgRenderer) defineArrow(opt *RenderOption) {
@@ -124,36 +258,55 @@ func (sr *SvgRenderer) defineArrow(opt *RenderOption) {
 
 	sr.Canvas.Def()
 
-	sr.beginMarker(selfDefinedArrowName, 0, float32(arrowSize)/2, arrowSize, arrowSize, arrowColor)
+	var arrowClass, highlightArrowClass string
+	if opt.CSSClasses {
+		arrowClass, highlightArrowClass = classArrow, classArrow+" "+classHighlighted
+	}
+	sr.addArrowMarker(selfDefinedArrowName, arrowSize, arrowColor, arrowClass)
+	if opt.Highlight != nil {
+		sr.addArrowMarker(selfDefinedHighlightArrowName, arrowSize, opt.highlightColor(), highlightArrowClass)
+	}
+
+	sr.Canvas.DefEnd()
+}
+
+// addArrowMarker defines an arrow marker, class is added to the marker if not empty.
+func (sr *SvgRenderer) addArrowMarker(id string, arrowSize float32, color, class string) {
+	sr.beginMarker(id, 0, float32(arrowSize)/2, arrowSize, arrowSize, color, class)
 	// define the path for marker
 	sr.Canvas.Path(fmt.Sprintf("M 0 0 L %.3f %.3f L 0 %.3f Z", arrowSize, float32(arrowSize)/2, arrowSize))
 
 	sr.endMarker()
-
-	sr.Canvas.DefEnd()
 }
 
 func (sr *SvgRenderer) addNode(node *PlaceableNode, radius int, opt *RenderOption) {
+	labelID := ""
+	if sr.elementKeys != nil {
+		labelID = elementID(opt.ElementIDPrefix, elementKindLabel, sr.elementKeys[node])
+	}
+	link, grouped := sr.beginNodeGroup(node, opt)
+	switch {
+	case node.Collapsed:
+		sr.addPlaceholder(node, labelID, opt)
+	case opt.CSSClasses:
+		sr.addClassedNode(node, labelID, opt)
+	default:
+		sr.addNodeCircle(node, labelID, opt)
+	}
+	sr.endNodeGroup(link, grouped)
+}
+
+// addNodeCircle renders node as a circle with inline styles, labelID is the id of the label if not empty.
+func (sr *SvgRenderer) addNodeCircle(node *PlaceableNode, labelID string, opt *RenderOption) {
 	// render node as a circle with radius centered at (node.x, node.y)
-	nodeStyleAttr := make([]svgStyleAttribute, 0, 1)
+	nodeStyleAttr := make([]svgStyleAttribute, 0, 4)
 
-	var nodeColor string
-	isLeaf := node.IsLeaf()
-	if node.Color == "" { // if no color is specified for this node locally, then use global color
-		if isLeaf {
-			nodeColor = opt.NodeLeafColor
-		} else {
-			nodeColor = opt.NodeColor
-		}
-		if nodeColor == "" {
-			nodeColor = DefaultNodeColor
-		}
-	} else {
-		nodeColor = node.Color
-	}
-	nodeStyleAttr = append(nodeStyleAttr, svgStyleAttribute{key: "fill", value: nodeColor})
+	nodeStyleAttr = append(nodeStyleAttr, svgStyleAttribute{key: "fill", value: nodeFillColor(node, opt, sr.nodeColors)})
 
-	if opt.NodeStrokeColor != "" {
+	if opt.Highlight != nil && node.Highlighted {
+		nodeStyleAttr = append(nodeStyleAttr, svgStyleAttribute{key: "stroke", value: opt.highlightColor()})
+		nodeStyleAttr = append(nodeStyleAttr, svgStyleAttribute{key: "stroke-width", value: strconv.Itoa(opt.highlightStrokeWidth())})
+	} else if opt.NodeStrokeColor != "" {
 		nodeStyleAttr = append(nodeStyleAttr, svgStyleAttribute{key: "stroke", value: opt.NodeStrokeColor})
 		var strokeWidth int = DefaultNodeStrokeWidth
 		if opt.NodeStrokeWidth != 0 {
@@ -161,39 +314,249 @@ func (sr *SvgRenderer) addNode(node *PlaceableNode, radius int, opt *RenderOptio
 		}
 		nodeStyleAttr = append(nodeStyleAttr, svgStyleAttribute{key: "stroke-width", value: strconv.Itoa(strokeWidth)})
 	}
+	if opt.Highlight != nil && !node.Highlighted {
+		nodeStyleAttr = append(nodeStyleAttr, svgStyleAttribute{key: "opacity", value: strconv.FormatFloat(opt.dimOpacity(), 'f', 3, 64)})
+	}
 
 	sr.constructCircle(node.X, node.Y, float32(opt.NodeRadius), []svgAttribute{
-		{key: "style", value: setSvgStyleAttributes(nodeStyleAttr)},
+		styleAttr(nodeStyleAttr),
 	})
 
-	sr.addText(node.X, node.Y, node.GetField(), opt)
+	sr.addText(node.X, node.Y, node.GetField(), labelID, opt, opt.Highlight != nil && !node.Highlighted)
 }
 
-func (sr *SvgRenderer) addText(x, y float32, text string, opt *RenderOption) {
-	var fontsize int = DefaultNodeFieldTextSize
-	if opt.NodeFieldTextSize != 0 {
-		fontsize = opt.NodeFieldTextSize
+// addClassedNode renders node styled by css classes, only the color specified for the single node is inlined.
+func (sr *SvgRenderer) addClassedNode(node *PlaceableNode, labelID string, opt *RenderOption) {
+	attrs := make([]svgAttribute, 0, 2)
+	if node.IsLeaf() {
+		attrs = append(attrs, classAttr(node, opt, classNode, classLeaf))
+	} else {
+		attrs = append(attrs, classAttr(node, opt, classNode))
 	}
+	if color := nodeOwnColor(node, opt, sr.nodeColors); color != "" {
+		attrs = append(attrs, styleAttr([]svgStyleAttribute{{key: "fill", value: color}}))
+	}
+	sr.constructCircle(node.X, node.Y, float32(opt.NodeRadius), attrs)
+
+	sr.addClassedText(node.X, node.Y, node.GetField(), labelID, classAttr(node, opt, classLabel), opt)
+}
+
+// nodeFillColor returns the fill color of node.
+// The local color of node is used first, translated by opt.NodeColorMap, then the color in colors chosen by
+// opt.NodePalette, then the global color.
+func nodeFillColor(node *PlaceableNode, opt *RenderOption, colors NodeColors) string {
+	if color := nodeOwnColor(node, opt, colors); color != "" {
+		return color
+	}
+
+	var nodeColor string
+	if node.IsLeaf() {
+		nodeColor = opt.NodeLeafColor
+	} else {
+		nodeColor = opt.NodeColor
+	}
+	if nodeColor == "" {
+		nodeColor = DefaultNodeColor
+	}
+	return nodeColor
+}
+
+// nodeOwnColor returns the color specified for the single node, by PaintableBiNode or by colors chosen
+// by opt.NodePalette. An empty string is returned if there is none.
+func nodeOwnColor(node *PlaceableNode, opt *RenderOption, colors NodeColors) string {
+	if node.Color != "" {
+		if mapped, ok := opt.NodeColorMap[node.Color]; ok {
+			return mapped
+		}
+		return node.Color
+	}
+	if colors != nil {
+		return colors.NodeColor(node)
+	}
+	return ""
+}
+
+// addPlaceholder renders a collapsed subtree as a triangle labelled with the hidden node count and height.
+func (sr *SvgRenderer) addPlaceholder(node *PlaceableNode, labelID string, opt *RenderOption) {
+	r := float32(opt.NodeRadius)
+	xs := []float32{node.X, node.X - r, node.X + r}
+	ys := []float32{node.Y - r, node.Y + r, node.Y + r}
+	label := placeholderLabel(node)
+	if opt.CSSClasses {
+		sr.constructPolygon(xs, ys, []svgAttribute{classAttr(node, opt, classPlaceholder)})
+		sr.addClassedText(node.X, node.Y+r/3, label, labelID, classAttr(node, opt, classLabel), opt)
+		return
+	}
+
+	color := DefaultPlaceholderColor
+	if opt.PlaceholderColor != "" {
+		color = opt.PlaceholderColor
+	}
+	placeholderStyleAttr := make([]svgStyleAttribute, 0, 3)
+	placeholderStyleAttr = append(placeholderStyleAttr, svgStyleAttribute{key: "fill", value: color})
+	if opt.NodeStrokeColor != "" {
+		placeholderStyleAttr = append(placeholderStyleAttr, svgStyleAttribute{key: "stroke", value: opt.NodeStrokeColor})
+	}
+	dimmed := opt.Highlight != nil && !node.Highlighted
+	if dimmed {
+		placeholderStyleAttr = append(placeholderStyleAttr, svgStyleAttribute{key: "opacity", value: strconv.FormatFloat(opt.dimOpacity(), 'f', 3, 64)})
+	}
+
+	sr.constructPolygon(xs, ys, []svgAttribute{
+		styleAttr(placeholderStyleAttr),
+	})
+
+	sr.addText(node.X, node.Y+r/3, label, labelID, opt, dimmed)
+}
+
+// placeholderLabel returns the label of a placeholder, made of the hidden node count and height.
+func placeholderLabel(node *PlaceableNode) string {
+	return fmt.Sprintf("+%d (h=%d)", node.HiddenNodes, node.HiddenHeight)
+}
+
+// addText renders text inside of node centred at (x, y), id is the id of the text element if not empty.
+func (sr *SvgRenderer) addText(x, y float32, text, id string, opt *RenderOption, dimmed bool) {
+	if opt.CSSClasses {
+		class := classAttr(nil, opt, classLabel)
+		if dimmed {
+			class = classAttr(nil, opt, classLabel, classDimmed)
+		}
+		sr.addClassedText(x, y, text, id, class, opt)
+		return
+	}
+	font := opt.nodeFieldFont()
 
 	// text style
-	textAttrs := make([]svgStyleAttribute, 0, 3)
+	textAttrs := make([]svgStyleAttribute, 0, 8)
 	textAttrs = append(textAttrs, svgStyleAttribute{key: "text-anchor", value: "middle"})
-	textAttrs = append(textAttrs, svgStyleAttribute{key: "font-size", value: fmt.Sprintf("%d", fontsize)})
+	textAttrs = append(textAttrs, svgStyleAttribute{key: "font-size", value: strconv.Itoa(int(font.Size))})
+	if font.Family != "" {
+		textAttrs = append(textAttrs, svgStyleAttribute{key: "font-family", value: font.Family})
+	}
+	if font.Weight != "" {
+		textAttrs = append(textAttrs, svgStyleAttribute{key: "font-weight", value: font.Weight})
+	}
+	if font.Style != "" {
+		textAttrs = append(textAttrs, svgStyleAttribute{key: "font-style", value: font.Style})
+	}
 	textcolor := DefaultNodeFieldTextColor
 	if opt.NodeFieldTextColor != "" {
 		textcolor = opt.NodeFieldTextColor
 	}
 	textAttrs = append(textAttrs, svgStyleAttribute{key: "fill", value: textcolor})
+	if dimmed {
+		textAttrs = append(textAttrs, svgStyleAttribute{key: "opacity", value: strconv.FormatFloat(opt.dimOpacity(), 'f', 3, 64)})
+	}
 
-	sr.constructText(x, y, text, []svgAttribute{
-		{key: "style", value: setSvgStyleAttributes(textAttrs)},
-		{key: "dy", value: fmt.Sprintf("%.3f", float32(fontsize)/3)},
-	})
+	var linesBuf [4]string
+	block := layoutNodeField(linesBuf[:0], text, opt)
+	tooltip := ""
+	if block.truncated {
+		tooltip = text
+	}
+
+	sr.constructText(x, y, block.lines, block.firstDy, block.lineHeight, tooltip, textAttrsWithID(styleAttr(textAttrs), id))
+}
+
+// addClassedText renders text inside of node centred at (x, y) with class, id is the id of the text element if not empty.
+func (sr *SvgRenderer) addClassedText(x, y float32, text, id string, class svgAttribute, opt *RenderOption) {
+	var linesBuf [4]string
+	block := layoutNodeField(linesBuf[:0], text, opt)
+	tooltip := ""
+	if block.truncated {
+		tooltip = text
+	}
+	sr.constructText(x, y, block.lines, block.firstDy, block.lineHeight, tooltip, textAttrsWithID(class, id))
+}
+
+// textAttrsWithID returns attr followed by the id attribute if id is not empty.
+// It does not append, so that the styles of attr can stay on the stack of the caller.
+func textAttrsWithID(attr svgAttribute, id string) []svgAttribute {
+	if id == "" {
+		return []svgAttribute{attr}
+	}
+	return []svgAttribute{attr, {key: "id", value: id}}
+}
+
+// edgeOffsetEnd returns how far edges end before the border of the child to leave room for an arrow.
+func edgeOffsetEnd(opt *RenderOption) float64 {
+	if !opt.EdgeWithArrow {
+		return 0
+	}
+	// arrow marker is specified
+	var arrowSize = DefaultEdgeArrowSize
+	if opt.EdgeArrowSize != 0 {
+		arrowSize = opt.EdgeArrowSize
+	}
+	return float64(arrowSize)
 }
 
 func (sr *SvgRenderer) addEdge(node *PlaceableNode, opt *RenderOption) {
+	edgeOffsetEnd := edgeOffsetEnd(opt)
+	for side, child := range []*PlaceableNode{node.Left, node.Right} {
+		if child == nil {
+			continue
+		}
+		route := routeEdge(opt.EdgeRouting, node, child, sr.root, float64(opt.NodeRadius), edgeOffsetEnd)
+		attrs := sr.edgeAttributes(child, edgeSide(side), opt)
+		if sr.elementKeys != nil {
+			// the shared attributes must not be modified
+			attrs = append(attrs[:len(attrs):len(attrs)], svgAttribute{
+				key:   "id",
+				value: elementID(opt.ElementIDPrefix, elementKindEdge, sr.elementKeys[child]),
+			})
+		}
+		sr.constructEdge(opt.EdgeRouting, route, attrs)
+		if label := edgeLabel(child); label != "" {
+			sr.addEdgeLabel(label, opt.EdgeRouting, route, edgeSide(side), opt.Highlight != nil && !child.HighlightedEdge, opt)
+		}
+	}
+}
+
+// edgeState is the state of an edge which decides its style.
+type edgeState int
+
+const (
+	edgeNormal edgeState = iota
+	edgeHighlighted
+	edgeDimmed
+	edgeStateCount
+)
+
+// edgeSide tells whether an edge goes to the left child or the right child.
+type edgeSide int
+
+const (
+	edgeLeft edgeSide = iota
+	edgeRight
+	edgeSideCount
+)
+
+// edgeAttributes returns the attributes of the edge which connects child on side to its parent.
+//
+// The attributes are shared by all edges in the same state on the same side, so they are built only once for each.
+func (sr *SvgRenderer) edgeAttributes(child *PlaceableNode, side edgeSide, opt *RenderOption) []svgAttribute {
+	state := edgeNormal
+	if opt.Highlight != nil {
+		if child.HighlightedEdge {
+			state = edgeHighlighted
+		} else {
+			state = edgeDimmed
+		}
+	}
+	if sr.edgeAttrs[state][side] == nil {
+		sr.edgeAttrs[state][side] = buildEdgeAttributes(state, side, opt)
+	}
+	return sr.edgeAttrs[state][side]
+}
+
+func buildEdgeAttributes(state edgeState, side edgeSide, opt *RenderOption) []svgAttribute {
+	if opt.CSSClasses {
+		return buildClassedEdgeAttributes(state, side, opt)
+	}
+
 	// set edge style attributes
-	edgeStyleAttr := make([]svgStyleAttribute, 0, 2)
+	edgeStyleAttr := make([]svgStyleAttribute, 0, 3)
 	var linewidth int = DefaultEdgeLineWidth
 	if opt.EdgeLineWidth != 0 {
 		linewidth = opt.EdgeLineWidth
@@ -202,60 +565,130 @@ func (sr *SvgRenderer) addEdge(node *PlaceableNode, opt *RenderOption) {
 	if opt.EdgeLineColor != "" {
 		linecolor = opt.EdgeLineColor
 	}
-	edgeStyleAttr = append(edgeStyleAttr, svgStyleAttribute{key: "stroke-width", value: fmt.Sprintf("%d", linewidth)})
+	arrowName := selfDefinedArrowName
+	switch state {
+	case edgeHighlighted:
+		linewidth = opt.highlightStrokeWidth()
+		linecolor = opt.highlightColor()
+		arrowName = selfDefinedHighlightArrowName
+	case edgeDimmed:
+		edgeStyleAttr = append(edgeStyleAttr, svgStyleAttribute{key: "opacity", value: strconv.FormatFloat(opt.dimOpacity(), 'f', 3, 64)})
+	}
+	edgeStyleAttr = append(edgeStyleAttr, svgStyleAttribute{key: "stroke-width", value: strconv.Itoa(linewidth)})
 	edgeStyleAttr = append(edgeStyleAttr, svgStyleAttribute{key: "stroke", value: linecolor})
-	edgeAttrStyleStr := setSvgStyleAttributes(edgeStyleAttr)
+	if opt.EdgeRouting != EdgeRoutingStraight {
+		edgeStyleAttr = append(edgeStyleAttr, svgStyleAttribute{key: "fill", value: "none"})
+	}
 
 	edgeAttr := []svgAttribute{
-		{key: "style", value: edgeAttrStyleStr},
+		styleAttr(edgeStyleAttr),
+	}
+	if opt.EdgeWithArrow {
+		edgeAttr = append(edgeAttr, svgAttribute{key: "marker-end", value: "url(#" + arrowName + ")"})
 	}
 
-	var edgeOffsetEnd float64 = 0
+	return edgeAttr
+}
+
+// buildClassedEdgeAttributes returns the attributes of edges styled by css classes.
+func buildClassedEdgeAttributes(state edgeState, side edgeSide, opt *RenderOption) []svgAttribute {
+	classes := []string{classEdge, classEdgeLeft}
+	if side == edgeRight {
+		classes[1] = classEdgeRight
+	}
+	arrowName := selfDefinedArrowName
+	switch state {
+	case edgeHighlighted:
+		classes = append(classes, classHighlighted)
+		arrowName = selfDefinedHighlightArrowName
+	case edgeDimmed:
+		classes = append(classes, classDimmed)
+	}
+
+	edgeAttr := []svgAttribute{classAttr(nil, opt, classes...)}
 	if opt.EdgeWithArrow {
-		// arrow marker is specified
-		var arrowSize = DefaultEdgeArrowSize
-		if opt.EdgeArrowSize != 0 {
-			arrowSize = opt.EdgeArrowSize
-		}
-		edgeOffsetEnd = float64(arrowSize)
-		edgeAttr = append(edgeAttr, svgAttribute{key: "marker-end", value: fmt.Sprintf("url(#%s)", selfDefinedArrowName)})
-	}
-
-	if node.Left != nil {
-		// left edge
-		edgeStartX, edgeStartY, edgeEndX, edgeEndY := measureEdgeStartEnd(
-			float64(node.X),
-			float64(node.Y),
-			float64(node.Left.X),
-			float64(node.Left.Y),
-			float64(opt.NodeRadius),
-			0,
-			edgeOffsetEnd,
-		)
-
-		sr.constructLine(edgeStartX, edgeStartY, edgeEndX, edgeEndY, edgeAttr)
-	}
-	if node.Right != nil {
-		// right edge
-		edgeStartX, edgeStartY, edgeEndX, edgeEndY := measureEdgeStartEnd(
-			float64(node.X),
-			float64(node.Y),
-			float64(node.Right.X),
-			float64(node.Right.Y),
-			float64(opt.NodeRadius),
-			0,
-			edgeOffsetEnd,
-		)
-		sr.constructLine(edgeStartX, edgeStartY, edgeEndX, edgeEndY, edgeAttr)
+		edgeAttr = append(edgeAttr, svgAttribute{key: "marker-end", value: "url(#" + arrowName + ")"})
 	}
+	return edgeAttr
+}
+
+// Sizes of the gradient legend of palette.
+const (
+	legendTextSize    = 12
+	legendBarHeight   = 12
+	legendGap         = 4
+	legendMaxBarWidth = 200
+)
+
+// legendHeight returns the height reserved below the tree for legend.
+func legendHeight(legend *GradientLegend) float32 {
+	height := legendBarHeight + legendGap + legendTextSize + legendGap*2
+	if legend.Title != "" {
+		height += legendTextSize + legendGap
+	}
+	return float32(height)
+}
+
+// defineLegendGradient defines the linear gradient filling the bar of legend.
+func (sr *SvgRenderer) defineLegendGradient(legend *GradientLegend) {
+	sr.Canvas.Def()
+	sr.svgCanvasBeginCustomShape("linearGradient", []svgAttribute{{key: "id", value: paletteGradientName}})
+	sr.svgCanvasAddCustomShape("stop", []svgAttribute{{key: "offset", value: "0"}, {key: "stop-color", value: legend.From}})
+	sr.svgCanvasAddCustomShape("stop", []svgAttribute{{key: "offset", value: "1"}, {key: "stop-color", value: legend.To}})
+	sr.svgCanvasEndCustomShape("linearGradient")
+	sr.Canvas.DefEnd()
+}
+
+// addLegend renders the gradient legend of palette at top, with the title above the bar
+// and the range of values below the bar.
+func (sr *SvgRenderer) addLegend(width, top float32, opt *RenderOption) {
+	x := float32(opt.horizontalPadding())
+	barWidth := minFloat32(legendMaxBarWidth, width-2*x)
+	y := top
+	if sr.legend.Title != "" {
+		sr.addLegendText(x, y+legendTextSize, sr.legend.Title, "start", opt)
+		y += legendTextSize + legendGap
+	}
+	sr.svgCanvasAddCustomShape("rect", []svgAttribute{
+		numAttr("x", float64(x)),
+		numAttr("y", float64(y)),
+		numAttr("width", float64(barWidth)),
+		numAttr("height", legendBarHeight),
+		{key: "fill", value: "url(#" + paletteGradientName + ")"},
+	})
+	y += legendBarHeight + legendGap + legendTextSize
+	sr.addLegendText(x, y, strconv.FormatFloat(sr.legend.Min, 'g', 4, 64), "start", opt)
+	sr.addLegendText(x+barWidth, y, strconv.FormatFloat(sr.legend.Max, 'g', 4, 64), "end", opt)
+}
+
+// addLegendText renders a line of text of legend with its baseline at y.
+func (sr *SvgRenderer) addLegendText(x, y float32, text, anchor string, opt *RenderOption) {
+	sr.addFrameText(float64(x), float64(y), []string{text}, legendTextSize, false, anchor, classLegend, opt)
 }
 
 func (sr *SvgRenderer) setGlobalBackgroundColor(w, h int, color string) {
 	sr.addRect(0, 0, w, h, color)
 }
 
+// addClassedBackground renders the background styled by css classes.
+func (sr *SvgRenderer) addClassedBackground(w, h int) {
+	sr.svgCanvasAddCustomShape("rect", []svgAttribute{
+		{key: "x", value: "0"},
+		{key: "y", value: "0"},
+		{key: "width", value: strconv.Itoa(w)},
+		{key: "height", value: strconv.Itoa(h)},
+		{key: "class", value: classBackground},
+	})
+}
+
 func (sr *SvgRenderer) addRect(x, y, w, h int, color string) {
-	sr.Canvas.Rect(x, y, w, h, "fill:"+color)
+	sr.svgCanvasAddCustomShape("rect", []svgAttribute{
+		{key: "x", value: strconv.Itoa(x)},
+		{key: "y", value: strconv.Itoa(y)},
+		{key: "width", value: strconv.Itoa(w)},
+		{key: "height", value: strconv.Itoa(h)},
+		styleAttr([]svgStyleAttribute{{key: "fill", value: color}}),
+	})
 }
 
 // svgStyleAttribute represents svg style attributes
@@ -264,46 +697,132 @@ type svgStyleAttribute struct {
 	value string
 }
 
-func setSvgStyleAttributes(attr []svgStyleAttribute) string {
-	attrStr := strings.Builder{}
+// appendSvgStyleAttributes appends attr formatted as the value of style attribute to b, with xml escaped.
+func appendSvgStyleAttributes(b []byte, attr []svgStyleAttribute) []byte {
 	for i, a := range attr {
-		attrStr.WriteString(a.key + ":" + a.value)
-		if i != len(attr)-1 {
-			attrStr.WriteByte(';')
+		if i != 0 {
+			b = append(b, ';')
 		}
+		b = appendEscapedXML(b, a.key)
+		b = append(b, ':')
+		b = appendEscapedXML(b, a.value)
 	}
 
-	return attrStr.String()
+	return b
 }
 
+// svgAttribute represents an attribute of svg element.
+//
+// If numeric is true, number is written as the value with 3 decimals instead of value.
+// If styles is not nil, styles are written as the value instead of value.
 type svgAttribute struct {
-	key   string
-	value string
+	key     string
+	value   string
+	number  float64
+	numeric bool
+	styles  []svgStyleAttribute
 }
 
-func (sr *SvgRenderer) svgCanvasBeginCustomShape(shape string, attrs []svgAttribute) {
-	attrBuilder := strings.Builder{}
+// numAttr returns a numeric svgAttribute.
+func numAttr(key string, number float64) svgAttribute {
+	return svgAttribute{key: key, number: number, numeric: true}
+}
+
+// styleAttr returns a style svgAttribute made of styles.
+func styleAttr(styles []svgStyleAttribute) svgAttribute {
+	return svgAttribute{key: "style", styles: styles}
+}
+
+// svgElementBufferPool pools the buffers used for formatting svg elements,
+// so that writing an element does not allocate.
+var svgElementBufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 256)
+		return &b
+	},
+}
+
+// appendSvgNumber appends f formatted with 3 decimals to b.
+// Tiny negative values, which come from rounding errors of trigonometry, are written as 0.000 instead of -0.000.
+func appendSvgNumber(b []byte, f float64) []byte {
+	if f < 0 && f > -0.0005 {
+		f = 0
+	}
+	return strconv.AppendFloat(b, f, 'f', 3, 64)
+}
+
+// Endings of the start tag written by writeCustomShape.
+const (
+	tagEndSelfClosing = "/>\n"
+	tagEndOpen        = ">\n"
+	// tagEndOpenInline is used when the content follows the start tag on the same line, for example tspan,
+	// so that no whitespace is added to the content.
+	tagEndOpenInline = ">"
+)
+
+// writeCustomShape writes the start tag of shape with attrs followed by locAttrs into the canvas.
+// The tag is ended with tagEnd, which is one of tagEndSelfClosing, tagEndOpen and tagEndOpenInline.
+func (sr *SvgRenderer) writeCustomShape(shape string, attrs, locAttrs []svgAttribute, tagEnd string) {
+	bp := svgElementBufferPool.Get().(*[]byte)
+	b := append((*bp)[:0], '<')
+	b = append(b, shape...)
+	b = append(b, ' ')
+	b = appendSvgAttributes(b, attrs)
+	b = appendSvgAttributes(b, locAttrs)
+	b = append(b, tagEnd...)
+	sr.buf.Write(b)
+
+	*bp = b
+	svgElementBufferPool.Put(bp)
+}
+
+// appendSvgAttributes appends attrs formatted as attributes of svg element to b, values are xml escaped.
+func appendSvgAttributes(b []byte, attrs []svgAttribute) []byte {
 	for _, attr := range attrs {
-		attrBuilder.WriteString(fmt.Sprintf(`%s="%s" `, attr.key, attr.value))
+		b = append(b, attr.key...)
+		b = append(b, '=', '"')
+		if attr.numeric {
+			b = appendSvgNumber(b, attr.number)
+		} else if attr.styles != nil {
+			b = appendSvgStyleAttributes(b, attr.styles)
+		} else {
+			b = appendEscapedXML(b, attr.value)
+		}
+		b = append(b, '"', ' ')
 	}
-	sr.buf.WriteString(fmt.Sprintf("<%s %s>\n", shape, attrBuilder.String()))
+	return b
+}
+
+func (sr *SvgRenderer) svgCanvasBeginCustomShape(shape string, attrs []svgAttribute) {
+	sr.writeCustomShape(shape, attrs, nil, tagEndOpen)
+}
+
+// svgCanvasBeginInlineShape writes the start tag of shape whose content follows on the same line.
+func (sr *SvgRenderer) svgCanvasBeginInlineShape(shape string, attrs []svgAttribute) {
+	sr.writeCustomShape(shape, attrs, nil, tagEndOpenInline)
 }
 
 func (sr *SvgRenderer) svgCanvasEndCustomShape(shape string) {
-	sr.buf.WriteString(fmt.Sprintf("</%s>\n", shape))
+	sr.buf.WriteString("</")
+	sr.buf.WriteString(shape)
+	sr.buf.WriteString(">\n")
 }
 
-func (sr *SvgRenderer) beginMarker(id string, refX, refY, width, height float32, color string) {
-	sr.svgCanvasBeginCustomShape("marker", []svgAttribute{
+func (sr *SvgRenderer) beginMarker(id string, refX, refY, width, height float32, color, class string) {
+	attrs := []svgAttribute{
 		{key: "id", value: id},
 		{key: "markerUnits", value: "userSpaceOnUse"},
-		{key: "refX", value: fmt.Sprintf("%.3f", refX)},
-		{key: "refY", value: fmt.Sprintf("%.3f", refY)},
-		{key: "markerWidth", value: fmt.Sprintf("%.3f", width)},
-		{key: "markerHeight", value: fmt.Sprintf("%.3f", height)},
+		numAttr("refX", float64(refX)),
+		numAttr("refY", float64(refY)),
+		numAttr("markerWidth", float64(width)),
+		numAttr("markerHeight", float64(height)),
 		{key: "fill", value: color},
 		{key: "orient", value: "auto"},
-	})
+	}
+	if class != "" {
+		attrs = append(attrs, svgAttribute{key: "class", value: class})
+	}
+	sr.svgCanvasBeginCustomShape("marker", attrs)
 }
 
 func (sr *SvgRenderer) endMarker() {
@@ -311,41 +830,77 @@ func (sr *SvgRenderer) endMarker() {
 }
 
 func (sr *SvgRenderer) svgCanvasAddCustomShape(shape string, attrs []svgAttribute) {
-	attrBuilder := strings.Builder{}
-	for _, attr := range attrs {
-		attrBuilder.WriteString(fmt.Sprintf(`%s="%s" `, attr.key, attr.value))
-	}
-	sr.buf.WriteString(fmt.Sprintf("<%s %s/>\n", shape, attrBuilder.String()))
+	sr.writeCustomShape(shape, attrs, nil, tagEndSelfClosing)
 }
 
 func (sr *SvgRenderer) constructLine(startX, startY, endX, endY float64, attrs []svgAttribute) {
-	locAttrs := []svgAttribute{
-		{key: "x1", value: fmt.Sprintf("%.3f", startX)},
-		{key: "y1", value: fmt.Sprintf("%.3f", startY)},
-		{key: "x2", value: fmt.Sprintf("%.3f", endX)},
-		{key: "y2", value: fmt.Sprintf("%.3f", endY)},
-	}
-	attrs = append(attrs, locAttrs...)
-	sr.svgCanvasAddCustomShape("line", attrs)
+	sr.writeCustomShape("line", attrs, []svgAttribute{
+		numAttr("x1", startX),
+		numAttr("y1", startY),
+		numAttr("x2", endX),
+		numAttr("y2", endY),
+	}, tagEndSelfClosing)
 }
 
 func (sr *SvgRenderer) constructCircle(cx, cy, radius float32, attrs []svgAttribute) {
-	locAttrs := []svgAttribute{
-		{key: "cx", value: fmt.Sprintf("%.3f", cx)},
-		{key: "cy", value: fmt.Sprintf("%.3f", cy)},
-		{key: "r", value: fmt.Sprintf("%.3f", radius)},
+	sr.writeCustomShape("circle", attrs, []svgAttribute{
+		numAttr("cx", float64(cx)),
+		numAttr("cy", float64(cy)),
+		numAttr("r", float64(radius)),
+	}, tagEndSelfClosing)
+}
+
+func (sr *SvgRenderer) constructPolygon(xs, ys []float32, attrs []svgAttribute) {
+	points := make([]byte, 0, len(xs)*16)
+	for i := range xs {
+		if i != 0 {
+			points = append(points, ' ')
+		}
+		points = appendSvgNumber(points, float64(xs[i]))
+		points = append(points, ',')
+		points = appendSvgNumber(points, float64(ys[i]))
 	}
-	attrs = append(attrs, locAttrs...)
-	sr.svgCanvasAddCustomShape("circle", attrs)
+	sr.writeCustomShape("polygon", attrs, []svgAttribute{{key: "points", value: string(points)}}, tagEndSelfClosing)
 }
 
-func (sr *SvgRenderer) constructText(x, y float32, text string, attrs []svgAttribute) {
-	locAttrs := []svgAttribute{
-		{key: "x", value: fmt.Sprintf("%.3f", x)},
-		{key: "y", value: fmt.Sprintf("%.3f", y)},
+// constructText writes lines as tspans of a text element, the first line is shifted by firstDy vertically
+// and the following lines are shifted by lineHeight each. If tooltip is not empty, it is shown when hovering.
+func (sr *SvgRenderer) constructText(x, y float32, lines []string, firstDy, lineHeight float64, tooltip string, attrs []svgAttribute) {
+	sr.writeCustomShape("text", attrs, []svgAttribute{
+		numAttr("x", float64(x)),
+		numAttr("y", float64(y)),
+	}, tagEndOpen)
+	if tooltip != "" {
+		sr.constructTitle(tooltip)
+	}
+	for i, line := range lines {
+		dy := lineHeight
+		if i == 0 {
+			dy = firstDy
+		}
+		sr.writeCustomShape("tspan", nil, []svgAttribute{
+			numAttr("x", float64(x)),
+			numAttr("dy", dy),
+		}, tagEndOpenInline)
+		sr.writeEscapedText(line)
+		sr.svgCanvasEndCustomShape("tspan")
 	}
-	attrs = append(attrs, locAttrs...)
-	sr.svgCanvasBeginCustomShape("text", attrs)
-	sr.Canvas.Writer.Write([]byte(text))
 	sr.svgCanvasEndCustomShape("text")
 }
+
+// constructTitle writes a title element, which is shown as a tooltip of its parent element.
+func (sr *SvgRenderer) constructTitle(title string) {
+	sr.buf.WriteString("<title>")
+	sr.writeEscapedText(title)
+	sr.buf.WriteString("</title>\n")
+}
+
+// writeEscapedText writes text into the canvas with xml escaped.
+func (sr *SvgRenderer) writeEscapedText(text string) {
+	bp := svgElementBufferPool.Get().(*[]byte)
+	b := appendEscapedXML((*bp)[:0], text)
+	sr.buf.Write(b)
+
+	*bp = b
+	svgElementBufferPool.Put(bp)
+}
@@ -54,7 +54,7 @@ func TestSvgRenderer_Dev(t *testing.T) {
 		EdgeWithArrow:     true,
 		EdgeArrowSize:     5,
 	}
-	pRoot = bitreevis.PerformLayout(pRoot, opt.SiblingSeparation, opt.NodeRadius, opt.LevelSeparation)
+	pRoot = bitreevis.LayoutWithOption(pRoot, &opt)
 
 	renderer := bitreevis.NewSvgRenderer()
 	result := renderer.Render(pRoot, &opt)
@@ -121,7 +121,7 @@ func TestSvgRenderer_LocalNodeColor(t *testing.T) {
 		EdgeWithArrow:      true,
 		EdgeArrowSize:      8,
 	}
-	pRoot = bitreevis.PerformLayout(pRoot, opt.SiblingSeparation, opt.NodeRadius, opt.LevelSeparation)
+	pRoot = bitreevis.LayoutWithOption(pRoot, &opt)
 
 	renderer := bitreevis.NewSvgRenderer()
 	result := renderer.Render(pRoot, &opt)
@@ -129,3 +129,16 @@ func TestSvgRenderer_LocalNodeColor(t *testing.T) {
 	require.Nil(t, result.Save("dev.svg"))
 	os.Remove("dev.svg")
 }
+
+func TestSvgRenderer_NoAllocationPerNode(t *testing.T) {
+	opt := (&bitreevis.RenderOption{EdgeWithArrow: true}).WithDefaults()
+	allocs := func(height int) float64 {
+		pRoot := bitreevis.LayoutWithOption(bitreevis.NewPlaceableTreeFromBiNode(newCompleteTreeForTest(height)), opt)
+		return testing.AllocsPerRun(5, func() {
+			bitreevis.NewSvgRenderer().Render(pRoot, opt)
+		})
+	}
+	// the allocations of a render grow with the size of the buffer, not with the number of nodes
+	small, large := allocs(6), allocs(10)
+	require.Less(t, (large-small)/float64(1<<10-1<<6), 0.01)
+}
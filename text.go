@@ -0,0 +1,143 @@
+package bitreevis
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TextOverflow specifies how a line of text wider than the maximum width is handled.
+type TextOverflow int
+
+const (
+	// TextOverflowEllipsis truncates the line and ends it with an ellipsis.
+	TextOverflowEllipsis TextOverflow = iota
+	// TextOverflowWrap breaks the line into several lines between words.
+	// Words wider than the maximum width are broken between characters.
+	TextOverflowWrap
+)
+
+const (
+	ellipsis = "…"
+
+	// defaultLineHeight is the height of a line of text relative to the font size.
+	defaultLineHeight = 1.2
+)
+
+// layoutLabel splits text into lines to be rendered and appends them to dst.
+//
+// text is split on '\n' first. If maxWidth is positive, each line wider than maxWidth is handled
+// according to overflow. If maxLines is positive, lines after maxLines are dropped and the last kept
+// line ends with an ellipsis. layoutLabel reports whether any part of text is dropped.
+func layoutLabel(dst []string, text string, maxWidth float64, overflow TextOverflow, maxLines int, measure func(string) float64) (lines []string, truncated bool) {
+	lines = dst
+	for rest, more := text, true; more; {
+		var p string
+		p, rest, more = strings.Cut(rest, "\n")
+		if maxWidth <= 0 || measure(p) <= maxWidth {
+			lines = append(lines, p)
+			continue
+		}
+		if overflow == TextOverflowWrap {
+			lines = append(lines, wrapLine(p, maxWidth, measure)...)
+		} else {
+			lines = append(lines, truncateLine(p, maxWidth, measure))
+			truncated = true
+		}
+	}
+
+	if maxLines > 0 && len(lines) > maxLines {
+		lines = lines[:maxLines]
+		last := strings.TrimRight(lines[maxLines-1], ellipsis)
+		if maxWidth > 0 {
+			lines[maxLines-1] = truncateLine(last, maxWidth, measure)
+		} else {
+			lines[maxLines-1] = last + ellipsis
+		}
+		truncated = true
+	}
+
+	return lines, truncated
+}
+
+// truncateLine removes characters from the end of line until it fits in maxWidth with an ellipsis appended.
+// The ellipsis is always appended even if line itself fits.
+func truncateLine(line string, maxWidth float64, measure func(string) float64) string {
+	runes := []rune(line)
+	for n := len(runes); n > 0; n-- {
+		candidate := string(runes[:n]) + ellipsis
+		if measure(candidate) <= maxWidth {
+			return candidate
+		}
+	}
+	return ellipsis
+}
+
+// wrapLine breaks line into lines which fit in maxWidth, between words if possible.
+func wrapLine(line string, maxWidth float64, measure func(string) float64) []string {
+	lines := make([]string, 0, 2)
+	cur := ""
+	for _, word := range strings.Fields(line) {
+		candidate := word
+		if cur != "" {
+			candidate = cur + " " + word
+		}
+		if measure(candidate) <= maxWidth {
+			cur = candidate
+			continue
+		}
+		if cur != "" {
+			lines = append(lines, cur)
+		}
+		// the word itself may be too wide
+		cur = ""
+		for _, r := range word {
+			if cur != "" && measure(cur+string(r)) > maxWidth {
+				lines = append(lines, cur)
+				cur = ""
+			}
+			cur += string(r)
+		}
+	}
+	if cur != "" || len(lines) == 0 {
+		lines = append(lines, cur)
+	}
+	return lines
+}
+
+// appendEscapedXML appends s to b with the characters which are special in xml escaped.
+// Characters which are not allowed in xml are replaced by U+FFFD.
+func appendEscapedXML(b []byte, s string) []byte {
+	last := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		width := 1
+		var esc string
+		switch {
+		case c == '&':
+			esc = "&amp;"
+		case c == '<':
+			esc = "&lt;"
+		case c == '>':
+			esc = "&gt;"
+		case c == '"':
+			esc = "&#34;"
+		case c == '\'':
+			esc = "&#39;"
+		case c < 0x20 && c != '\t' && c != '\n' && c != '\r':
+			esc = "\uFFFD"
+		case c >= utf8.RuneSelf:
+			var r rune
+			r, width = utf8.DecodeRuneInString(s[i:])
+			if r == 0xFFFE || r == 0xFFFF || (r == utf8.RuneError && width == 1) {
+				esc = "\uFFFD"
+			}
+		}
+		if esc != "" {
+			b = append(b, s[last:i]...)
+			b = append(b, esc...)
+			last = i + width
+		}
+		i += width
+	}
+	return append(b, s[last:]...)
+}
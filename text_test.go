@@ -0,0 +1,105 @@
+package bitreevis_test
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+type labelNode struct {
+	Left  *labelNode
+	Right *labelNode
+	Label string
+}
+
+func (n *labelNode) GetLeftChild() bitreevis.BiNode {
+	return n.Left
+}
+
+func (n *labelNode) GetRightChild() bitreevis.BiNode {
+	return n.Right
+}
+
+func (n *labelNode) GetField() string {
+	return n.Label
+}
+
+func renderForTest(t *testing.T, root bitreevis.BiNode, opt *bitreevis.RenderOption) string {
+	opt = opt.WithDefaults()
+	pRoot := bitreevis.NewPlaceableTreeFromBiNodeWithOption(root, opt)
+	pRoot = bitreevis.LayoutWithOption(pRoot, opt)
+	result := bitreevis.NewSvgRenderer().Render(pRoot, opt)
+	require.Nil(t, result.Error())
+	content, err := io.ReadAll(result.GetContent())
+	require.Nil(t, err)
+	return string(content)
+}
+
+// requireWellFormed checks that content is a well-formed xml document.
+func requireWellFormed(t *testing.T, content string) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return
+		}
+		require.Nil(t, err)
+	}
+}
+
+func TestSvgRenderer_EscapeLabel(t *testing.T) {
+	root := &labelNode{Label: `a<b & "c"`, Left: &labelNode{Label: "x\x01y"}}
+	content := renderForTest(t, root, &bitreevis.RenderOption{NodeColor: `red"`})
+	requireWellFormed(t, content)
+	require.Contains(t, content, "a&lt;b &amp; &#34;c&#34;")
+	require.Contains(t, content, "x�y")
+	require.Contains(t, content, "fill:red&#34;")
+}
+
+func TestSvgRenderer_MultiLineLabel(t *testing.T) {
+	root := &labelNode{Label: "first\nsecond\nthird"}
+	content := renderForTest(t, root, &bitreevis.RenderOption{NodeFieldTextSize: 10})
+	requireWellFormed(t, content)
+	require.Equal(t, 3, strings.Count(content, "<tspan"))
+	// the lines are centred vertically: cap height of helvetica 7.18 and line height 12
+	require.Contains(t, content, `dy="-8.410" >first</tspan>`)
+	require.Equal(t, 2, strings.Count(content, `dy="12.000"`))
+	require.NotContains(t, content, "<title>")
+}
+
+func TestSvgRenderer_TruncateLabel(t *testing.T) {
+	root := &labelNode{Label: "a very long label"}
+	content := renderForTest(t, root, &bitreevis.RenderOption{NodeFieldTextSize: 10, NodeFieldMaxWidth: 36})
+	requireWellFormed(t, content)
+	require.Contains(t, content, ">a very…</tspan>")
+	require.Contains(t, content, "<title>a very long label</title>")
+}
+
+func TestSvgRenderer_WrapLabel(t *testing.T) {
+	root := &labelNode{Label: "a very long label"}
+	content := renderForTest(t, root, &bitreevis.RenderOption{
+		NodeFieldTextSize: 10,
+		NodeFieldMaxWidth: 36,
+		NodeFieldOverflow: bitreevis.TextOverflowWrap,
+	})
+	requireWellFormed(t, content)
+	for _, line := range []string{">a very</tspan>", ">long</tspan>", ">label</tspan>"} {
+		require.Contains(t, content, line)
+	}
+	require.NotContains(t, content, "<title>")
+
+	content = renderForTest(t, root, &bitreevis.RenderOption{
+		NodeFieldTextSize: 10,
+		NodeFieldMaxWidth: 36,
+		NodeFieldOverflow: bitreevis.TextOverflowWrap,
+		NodeFieldMaxLines: 2,
+	})
+	require.Equal(t, 2, strings.Count(content, "<tspan"))
+	require.Contains(t, content, ">long…</tspan>")
+	require.Contains(t, content, "<title>a very long label</title>")
+}
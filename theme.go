@@ -0,0 +1,209 @@
+package bitreevis
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Names of built-in themes.
+const (
+	ThemeLight        = "light"
+	ThemeDark         = "dark"
+	ThemeHighContrast = "high-contrast"
+	ThemePrint        = "print"
+	ThemeColorBlind   = "colorblind"
+	ThemeCLRSRedBlack = "clrs-red-black"
+)
+
+// A Theme is a named set of colors and widths which populates RenderOption.
+//
+// Empty fields of a Theme leave the corresponding fields of RenderOption unchanged.
+type Theme struct {
+	BackgroundColor    string
+	NodeColor          string
+	NodeLeafColor      string
+	NodeStrokeColor    string
+	NodeStrokeWidth    int
+	NodeFieldTextColor string
+	EdgeLineColor      string
+	EdgeLineWidth      int
+	HighlightColor     string
+	PlaceholderColor   string
+	// NodeColorMap translates the colors of PaintableBiNode into the colors of the theme.
+	NodeColorMap map[string]string
+}
+
+var (
+	themesMu sync.RWMutex
+	themes   = map[string]Theme{
+		ThemeLight: {
+			BackgroundColor:    "#ffffff",
+			NodeColor:          "#90caf9",
+			NodeLeafColor:      "#a5d6a7",
+			NodeStrokeColor:    "#37474f",
+			NodeFieldTextColor: "#212121",
+			EdgeLineColor:      "#546e7a",
+			HighlightColor:     "#ff6f00",
+			PlaceholderColor:   "#eceff1",
+		},
+		ThemeDark: {
+			BackgroundColor:    "#121212",
+			NodeColor:          "#3949ab",
+			NodeLeafColor:      "#00897b",
+			NodeStrokeColor:    "#e0e0e0",
+			NodeFieldTextColor: "#ffffff",
+			EdgeLineColor:      "#b0bec5",
+			HighlightColor:     "#ffab40",
+			PlaceholderColor:   "#424242",
+		},
+		ThemeHighContrast: {
+			BackgroundColor:    "#000000",
+			NodeColor:          "#000000",
+			NodeLeafColor:      "#000000",
+			NodeStrokeColor:    "#ffffff",
+			NodeStrokeWidth:    3,
+			NodeFieldTextColor: "#ffff00",
+			EdgeLineColor:      "#ffffff",
+			EdgeLineWidth:      3,
+			HighlightColor:     "#00ffff",
+			PlaceholderColor:   "#333333",
+		},
+		ThemePrint: {
+			BackgroundColor:    "#ffffff",
+			NodeColor:          "#ffffff",
+			NodeLeafColor:      "#e0e0e0",
+			NodeStrokeColor:    "#000000",
+			NodeStrokeWidth:    1,
+			NodeFieldTextColor: "#000000",
+			EdgeLineColor:      "#000000",
+			HighlightColor:     "#000000",
+			PlaceholderColor:   "#bdbdbd",
+			NodeColorMap: map[string]string{
+				"red":   "#bdbdbd",
+				"black": "#616161",
+			},
+		},
+		// colors of the Okabe-Ito palette, which are distinguishable with color vision deficiency
+		ThemeColorBlind: {
+			BackgroundColor:    "#ffffff",
+			NodeColor:          "#56b4e9",
+			NodeLeafColor:      "#009e73",
+			NodeStrokeColor:    "#000000",
+			NodeFieldTextColor: "#000000",
+			EdgeLineColor:      "#000000",
+			HighlightColor:     "#d55e00",
+			PlaceholderColor:   "#f0e442",
+			NodeColorMap: map[string]string{
+				"red":    "#d55e00",
+				"green":  "#009e73",
+				"blue":   "#0072b2",
+				"yellow": "#f0e442",
+				"orange": "#e69f00",
+			},
+		},
+		// red-black trees as drawn in Introduction to Algorithms
+		ThemeCLRSRedBlack: {
+			BackgroundColor:    "#ffffff",
+			NodeColor:          "#212121",
+			NodeLeafColor:      "#212121",
+			NodeStrokeColor:    "#000000",
+			NodeFieldTextColor: "#ffffff",
+			EdgeLineColor:      "#000000",
+			HighlightColor:     "#1e88e5",
+			PlaceholderColor:   "#9e9e9e",
+			NodeColorMap: map[string]string{
+				"red":   "#c62828",
+				"black": "#212121",
+			},
+		},
+	}
+)
+
+// RegisterTheme registers theme with name, so that it can be applied by name.
+// A theme registered with the same name before, including the built-in ones, is replaced.
+// The NodeColorMap of theme is copied, so changing it later does not change the registered theme.
+func RegisterTheme(name string, theme Theme) {
+	theme.NodeColorMap = copyColorMap(theme.NodeColorMap)
+	themesMu.Lock()
+	defer themesMu.Unlock()
+	themes[name] = theme
+}
+
+// UnregisterTheme removes the theme registered with name, if there is one.
+func UnregisterTheme(name string) {
+	themesMu.Lock()
+	defer themesMu.Unlock()
+	delete(themes, name)
+}
+
+// LookupTheme returns a copy of the theme registered with name, which can be changed freely.
+func LookupTheme(name string) (Theme, bool) {
+	themesMu.RLock()
+	defer themesMu.RUnlock()
+	theme, ok := themes[name]
+	theme.NodeColorMap = copyColorMap(theme.NodeColorMap)
+	return theme, ok
+}
+
+// ThemeNames returns the names of all registered themes in sorted order.
+func ThemeNames() []string {
+	themesMu.RLock()
+	defer themesMu.RUnlock()
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply populates the fields of opt with the non-empty fields of the theme.
+// The NodeColorMap of the theme is copied into opt.
+func (t Theme) Apply(opt *RenderOption) {
+	setThemeString(&opt.BackgroundColor, t.BackgroundColor)
+	setThemeString(&opt.NodeColor, t.NodeColor)
+	setThemeString(&opt.NodeLeafColor, t.NodeLeafColor)
+	setThemeString(&opt.NodeStrokeColor, t.NodeStrokeColor)
+	setThemeString(&opt.NodeFieldTextColor, t.NodeFieldTextColor)
+	setThemeString(&opt.EdgeLineColor, t.EdgeLineColor)
+	setThemeString(&opt.HighlightColor, t.HighlightColor)
+	setThemeString(&opt.PlaceholderColor, t.PlaceholderColor)
+	if t.NodeStrokeWidth != 0 {
+		opt.NodeStrokeWidth = t.NodeStrokeWidth
+	}
+	if t.EdgeLineWidth != 0 {
+		opt.EdgeLineWidth = t.EdgeLineWidth
+	}
+	if t.NodeColorMap != nil {
+		opt.NodeColorMap = copyColorMap(t.NodeColorMap)
+	}
+}
+
+// copyColorMap returns a copy of m, nil if m is nil.
+func copyColorMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func setThemeString(v *string, value string) {
+	if value != "" {
+		*v = value
+	}
+}
+
+// ApplyTheme populates the fields of opt with the theme registered with name.
+func (opt *RenderOption) ApplyTheme(name string) error {
+	theme, ok := LookupTheme(name)
+	if !ok {
+		return fmt.Errorf("bitreevis: unknown theme %q", name)
+	}
+	theme.Apply(opt)
+	return nil
+}
@@ -0,0 +1,140 @@
+package bitreevis_test
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+func TestTheme_Apply(t *testing.T) {
+	for _, name := range []string{bitreevis.ThemeLight, bitreevis.ThemeDark, bitreevis.ThemeHighContrast,
+		bitreevis.ThemePrint, bitreevis.ThemeColorBlind, bitreevis.ThemeCLRSRedBlack} {
+		require.Contains(t, bitreevis.ThemeNames(), name)
+		opt := &bitreevis.RenderOption{}
+		require.Nil(t, opt.ApplyTheme(name))
+		require.NotEmpty(t, opt.BackgroundColor, name)
+		require.NotEmpty(t, opt.NodeColor, name)
+	}
+	require.NotNil(t, (&bitreevis.RenderOption{}).ApplyTheme("no-such-theme"))
+
+	name := "test-" + t.Name()
+	bitreevis.RegisterTheme(name, bitreevis.Theme{NodeColor: "#123456"})
+	t.Cleanup(func() { bitreevis.UnregisterTheme(name) })
+	opt := &bitreevis.RenderOption{BackgroundColor: "#eeeeee"}
+	require.Nil(t, opt.ApplyTheme(name))
+	require.Equal(t, "#123456", opt.NodeColor)
+	require.Equal(t, "#eeeeee", opt.BackgroundColor)
+}
+
+func TestTheme_NodeColorMap(t *testing.T) {
+	root := &rbNode{Value: 2, Color: "black", Left: &rbNode{Value: 1, Color: "red"}}
+	opt := &bitreevis.RenderOption{}
+	require.Nil(t, opt.ApplyTheme(bitreevis.ThemeCLRSRedBlack))
+	content := renderForTest(t, root, opt)
+	require.Contains(t, content, "fill:#c62828")
+	require.Contains(t, content, "fill:#212121")
+	require.NotContains(t, content, "fill:red")
+}
+
+func TestPalette_DepthAndSubtree(t *testing.T) {
+	content := renderForTest(t, newBstForTest(), &bitreevis.RenderOption{
+		NodePalette: bitreevis.DepthPalette("#000001", "#000002"),
+	})
+	require.Contains(t, content, "fill:#000001")
+	require.Contains(t, content, "fill:#000002")
+
+	content = renderForTest(t, newBstForTest(), &bitreevis.RenderOption{
+		NodeColor:   "#000009",
+		NodePalette: bitreevis.SubtreePalette(1, "#00000a", "#00000b"),
+	})
+	require.Contains(t, content, "fill:#000009")
+	require.Contains(t, content, "fill:#00000a")
+	require.Contains(t, content, "fill:#00000b")
+}
+
+func TestPalette_Gradient(t *testing.T) {
+	value := func(node bitreevis.BiNode) float64 {
+		v, _ := strconv.Atoi(node.GetField())
+		return float64(v)
+	}
+	palette := bitreevis.NewGradientPalette(value, "#000000", "#ffffff")
+	palette.Title = "value"
+	content := renderForTest(t, newBstForTest(), &bitreevis.RenderOption{NodePalette: palette})
+	requireWellFormed(t, content)
+	// values range from 1 to 9
+	require.Contains(t, content, "fill:#000000")
+	require.Contains(t, content, "fill:#ffffff")
+	require.Contains(t, content, "fill:#808080")
+	require.Contains(t, content, "linearGradient")
+	colors := palette.Prepare(bitreevis.NewPlaceableTreeFromBiNode(newBstForTest()))
+	require.Equal(t, bitreevis.GradientLegend{Title: "value", From: "#000000", To: "#ffffff", Min: 1, Max: 9},
+		colors.(bitreevis.LegendNodeColors).Legend())
+
+	err := (&bitreevis.RenderOption{NodePalette: bitreevis.NewGradientPalette(value, "black", "#fff")}).Validate()
+	require.True(t, errors.Is(err, bitreevis.ErrInvalidOption))
+}
+
+func TestPalette_SharedByConcurrentRenderings(t *testing.T) {
+	value := func(node bitreevis.BiNode) float64 {
+		v, _ := strconv.Atoi(node.GetField())
+		return float64(v)
+	}
+	for _, palette := range []bitreevis.Palette{
+		bitreevis.DepthPalette(),
+		bitreevis.SubtreePalette(1),
+		bitreevis.NewGradientPalette(value, "#000000", "#ffffff"),
+	} {
+		opt := &bitreevis.RenderOption{NodePalette: palette}
+		trees := []func() bitreevis.BiNode{
+			func() bitreevis.BiNode { return newBstForTest() },
+			func() bitreevis.BiNode { return newCompleteTreeForTest(5) },
+		}
+		expected := make([]string, len(trees))
+		for i, tree := range trees {
+			expected[i] = renderForTest(t, tree(), opt)
+		}
+
+		var wg sync.WaitGroup
+		actual := make([][]byte, 8)
+		for i := range actual {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				o := opt.WithDefaults()
+				pRoot := bitreevis.LayoutWithOption(bitreevis.NewPlaceableTreeFromBiNode(trees[i%len(trees)]()), o)
+				actual[i], _ = io.ReadAll(bitreevis.NewSvgRenderer().Render(pRoot, o).GetContent())
+			}(i)
+		}
+		wg.Wait()
+		for i := range actual {
+			require.Equal(t, expected[i%len(trees)], string(actual[i]))
+		}
+	}
+}
+
+func TestTheme_NodeColorMapIsCopied(t *testing.T) {
+	opt := &bitreevis.RenderOption{}
+	require.Nil(t, opt.ApplyTheme(bitreevis.ThemeCLRSRedBlack))
+	opt.NodeColorMap["red"] = "#000000"
+	theme, ok := bitreevis.LookupTheme(bitreevis.ThemeCLRSRedBlack)
+	require.True(t, ok)
+	require.Equal(t, "#c62828", theme.NodeColorMap["red"])
+
+	theme.NodeColorMap["red"] = "#000000"
+	theme, _ = bitreevis.LookupTheme(bitreevis.ThemeCLRSRedBlack)
+	require.Equal(t, "#c62828", theme.NodeColorMap["red"])
+
+	colors := map[string]string{"red": "#ff0000"}
+	name := "test-" + t.Name()
+	bitreevis.RegisterTheme(name, bitreevis.Theme{NodeColorMap: colors})
+	t.Cleanup(func() { bitreevis.UnregisterTheme(name) })
+	colors["red"] = "#000000"
+	theme, _ = bitreevis.LookupTheme(name)
+	require.Equal(t, "#ff0000", theme.NodeColorMap["red"])
+}
@@ -0,0 +1,169 @@
+package bitreevis
+
+// NewPlaceableTreeFromBiNodeWithOption builds a tree made of PlaceableNode from a tree made of BiNode,
+// respecting the size limits in opt.
+//
+// Conversion stops at opt.MaxDepth levels below the visible root or after opt.MaxNodes nodes are
+// converted in level order, whichever comes first. Every truncated subtree is replaced by a
+// collapsed placeholder node which records the number of hidden nodes and the height of the subtree.
+//
+// If opt.Focus is set, the visible root is the ancestor of opt.Focus which is opt.MaxDepth/2 levels
+// above it, so that the focus is in the middle of the visible window. The nodes on the path to the
+// focus are always converted even if opt.MaxNodes is exceeded.
+func NewPlaceableTreeFromBiNodeWithOption(root BiNode, opt *RenderOption) *PlaceableNode {
+	if opt == nil || (opt.MaxDepth <= 0 && opt.MaxNodes <= 0 && biNodeIsNil(opt.Focus)) {
+		return NewPlaceableTreeFromBiNode(root)
+	}
+	if biNodeIsNil(root) {
+		return nil
+	}
+
+	var keep []pathStep
+	if !biNodeIsNil(opt.Focus) {
+		path := findPath(root, opt.Focus)
+		if len(path) != 0 {
+			start := len(path) - 1 - opt.MaxDepth/2
+			if start < 0 || opt.MaxDepth <= 0 {
+				start = 0
+			}
+			root = path[start].node
+			keep = path[start:]
+		}
+	}
+
+	return buildPlaceableTree(root, opt.MaxDepth, opt.MaxNodes, keep)
+}
+
+type convertItem struct {
+	src    BiNode
+	parent *PlaceableNode
+	isLeft bool
+	depth  int
+	// kept reports whether src is on the path which is always converted.
+	kept bool
+}
+
+// buildPlaceableTree builds the tree in level order, so that nodes near the root are kept
+// when the number of nodes exceeds maxNodes. The nodes on the path keep, which starts at root,
+// are always converted. Zero maxDepth or maxNodes means no limit.
+//
+// Nodes are never hashed or compared, so that any type of BiNode can be converted.
+// It uses an explicit queue instead of recursion, so that degenerate trees with millions of levels can be handled.
+func buildPlaceableTree(root BiNode, maxDepth, maxNodes int, keep []pathStep) *PlaceableNode {
+	var pRoot *PlaceableNode
+	arena := &placeableNodeArena{}
+	converted := 0
+	queue := []convertItem{{src: root, kept: len(keep) != 0}}
+	for head := 0; head < len(queue); head++ {
+		if head >= minArenaChunkSize && head*2 >= len(queue) {
+			// reuse the space of the consumed items
+			queue = queue[:copy(queue, queue[head:])]
+			head = 0
+		}
+		item := queue[head]
+
+		var pNode *PlaceableNode
+		exceeded := (maxDepth > 0 && item.depth > maxDepth) || (maxNodes > 0 && converted >= maxNodes)
+		if exceeded && !item.kept {
+			pNode = arena.newCollapsedNode(item.src, item.depth)
+		} else {
+			pNode = arena.newPlaceableNodeFromBiNode(item.src, item.depth)
+			converted++
+			next := item.depth + 1
+			keepNext := item.kept && next < len(keep)
+			if left := item.src.GetLeftChild(); !biNodeIsNil(left) {
+				queue = append(queue, convertItem{src: left, parent: pNode, isLeft: true, depth: next,
+					kept: keepNext && keep[next].isLeft})
+			}
+			if right := item.src.GetRightChild(); !biNodeIsNil(right) {
+				queue = append(queue, convertItem{src: right, parent: pNode, depth: next,
+					kept: keepNext && !keep[next].isLeft})
+			}
+		}
+
+		pNode.Parent = item.parent
+		switch {
+		case item.parent == nil:
+			pRoot = pNode
+		case item.isLeft:
+			item.parent.Left = pNode
+		default:
+			item.parent.Right = pNode
+		}
+	}
+
+	return pRoot
+}
+
+// newCollapsedNode returns a placeholder standing for the subtree rooted at src.
+func (a *placeableNodeArena) newCollapsedNode(src BiNode, depth int) *PlaceableNode {
+	pNode := a.alloc()
+	pNode.Source = src
+	pNode.Depth = depth
+	pNode.Collapsed = true
+	pNode.HiddenNodes, pNode.HiddenHeight = countNodes(src)
+	return pNode
+}
+
+// countNodes returns the number of nodes and the height of the tree rooted at root.
+func countNodes(root BiNode) (count, height int) {
+	if biNodeIsNil(root) {
+		return 0, 0
+	}
+	level := []BiNode{root}
+	for len(level) > 0 {
+		height++
+		count += len(level)
+		next := make([]BiNode, 0, len(level)*2)
+		for _, node := range level {
+			if left := node.GetLeftChild(); !biNodeIsNil(left) {
+				next = append(next, left)
+			}
+			if right := node.GetRightChild(); !biNodeIsNil(right) {
+				next = append(next, right)
+			}
+		}
+		level = next
+	}
+	return
+}
+
+// pathStep is a node on a path from the root of a tree.
+type pathStep struct {
+	node BiNode
+	// isLeft reports whether node is the left child of the previous node on the path.
+	isLeft bool
+}
+
+// findPath returns the nodes on the path from root to target, both inclusive.
+// If target is not in the tree, findPath returns nil.
+func findPath(root, target BiNode) []pathStep {
+	type frame struct {
+		pathStep
+		visited bool
+	}
+	path := make([]pathStep, 0)
+	stack := []frame{{pathStep: pathStep{node: root}}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.visited {
+			// all descendants are searched, leave this node
+			stack = stack[:len(stack)-1]
+			path = path[:len(path)-1]
+			continue
+		}
+		top.visited = true
+		node := top.node
+		path = append(path, top.pathStep)
+		if sameBiNode(node, target) {
+			return path
+		}
+		if right := node.GetRightChild(); !biNodeIsNil(right) {
+			stack = append(stack, frame{pathStep: pathStep{node: right}})
+		}
+		if left := node.GetLeftChild(); !biNodeIsNil(left) {
+			stack = append(stack, frame{pathStep: pathStep{node: left, isLeft: true}})
+		}
+	}
+	return nil
+}
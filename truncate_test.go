@@ -0,0 +1,137 @@
+package bitreevis_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanreadbooks/bitreevis"
+)
+
+// newCompleteTreeForTest returns a complete binary tree of the given height whose nodes are numbered in level order.
+func newCompleteTreeForTest(height int) *myNode {
+	nodes := make([]*myNode, (1<<height)-1)
+	for i := range nodes {
+		nodes[i] = &myNode{Value: i}
+		if i > 0 {
+			parent := nodes[(i-1)/2]
+			if i%2 == 1 {
+				parent.Left = nodes[i]
+			} else {
+				parent.Right = nodes[i]
+			}
+		}
+	}
+	return nodes[0]
+}
+
+func TestNewPlaceableTreeFromBiNodeWithOption_MaxDepth(t *testing.T) {
+	root := newCompleteTreeForTest(5)
+	pRoot := bitreevis.NewPlaceableTreeFromBiNodeWithOption(root, &bitreevis.RenderOption{MaxDepth: 2})
+
+	nodes := pRoot.CollectNodes()
+	require.Len(t, nodes, 15)
+	collapsed := 0
+	for _, node := range nodes {
+		if node.Collapsed {
+			collapsed++
+			require.Equal(t, 3, node.Depth)
+			require.Equal(t, 3, node.HiddenNodes)
+			require.Equal(t, 2, node.HiddenHeight)
+			require.True(t, node.IsLeaf())
+		}
+	}
+	require.Equal(t, 8, collapsed)
+}
+
+func TestNewPlaceableTreeFromBiNodeWithOption_MaxNodes(t *testing.T) {
+	root := newCompleteTreeForTest(4)
+	pRoot := bitreevis.NewPlaceableTreeFromBiNodeWithOption(root, &bitreevis.RenderOption{MaxNodes: 4})
+
+	visible, hidden := 0, 0
+	for _, node := range pRoot.CollectNodes() {
+		if node.Collapsed {
+			hidden += node.HiddenNodes
+		} else {
+			visible++
+		}
+	}
+	require.Equal(t, 4, visible)
+	require.Equal(t, 15-4, hidden)
+}
+
+func TestNewPlaceableTreeFromBiNodeWithOption_Focus(t *testing.T) {
+	root := newCompleteTreeForTest(6)
+	focus := root.Right.Left.Right.Left // depth 4
+	pRoot := bitreevis.NewPlaceableTreeFromBiNodeWithOption(root, &bitreevis.RenderOption{MaxDepth: 4, Focus: focus})
+
+	// the window starts two levels above the focus
+	require.Equal(t, root.Right.Left.GetField(), pRoot.Field)
+	require.Equal(t, 0, pRoot.Depth)
+	require.Equal(t, focus.GetField(), pRoot.Right.Left.Field)
+	require.Equal(t, 2, pRoot.Right.Left.Depth)
+}
+
+func TestNewPlaceableTreeFromBiNodeWithOption_FocusBeyondMaxNodes(t *testing.T) {
+	root := newCompleteTreeForTest(6)
+	focus := root.Left.Right.Right.Left.Right // depth 5
+	pRoot := bitreevis.NewPlaceableTreeFromBiNodeWithOption(root, &bitreevis.RenderOption{MaxNodes: 3, Focus: focus})
+
+	node := pRoot
+	for _, left := range []bool{true, false, false, true, false} {
+		if left {
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+		require.False(t, node.Collapsed)
+	}
+	require.Equal(t, focus.GetField(), node.Field)
+	require.True(t, pRoot.Right.Left.Collapsed)
+	require.True(t, pRoot.Left.Left.Collapsed)
+}
+
+func TestNewPlaceableTreeFromBiNodeWithOption_UncomparableNodes(t *testing.T) {
+	root := sliceNode{values: make([]int, 15)}
+	for i := range root.values {
+		root.values[i] = i
+	}
+	for _, opt := range []*bitreevis.RenderOption{
+		{MaxDepth: 2},
+		{MaxNodes: 4},
+		{MaxNodes: 4, Focus: sliceNode{values: root.values, index: 13}},
+	} {
+		var pRoot *bitreevis.PlaceableNode
+		require.NotPanics(t, func() { pRoot = bitreevis.NewPlaceableTreeFromBiNodeWithOption(root, opt) })
+		visible, hidden := 0, 0
+		for _, node := range pRoot.CollectNodes() {
+			if node.Collapsed {
+				hidden += node.HiddenNodes
+			} else {
+				visible++
+			}
+		}
+		require.Equal(t, 15, visible+hidden)
+		require.Less(t, visible, 15)
+	}
+}
+
+func TestSvgRenderer_Placeholder(t *testing.T) {
+	opt := bitreevis.RenderOption{
+		SiblingSeparation: 20,
+		LevelSeparation:   20,
+		NodeRadius:        20,
+		MaxDepth:          1,
+	}
+	pRoot := bitreevis.NewPlaceableTreeFromBiNodeWithOption(newCompleteTreeForTest(4), &opt)
+	pRoot = bitreevis.LayoutWithOption(pRoot, &opt)
+
+	result := bitreevis.NewSvgRenderer().Render(pRoot, &opt)
+	require.Nil(t, result.Error())
+	content, err := io.ReadAll(result.GetContent())
+	require.Nil(t, err)
+	require.Equal(t, 4, strings.Count(string(content), "<polygon"))
+	require.Contains(t, string(content), "+3 (h=2)")
+}